@@ -0,0 +1,222 @@
+package PayrollRecord
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// InputFields are the names of the input record fields, in the column order createPayrollRecord reads them.
+// The first five are required, the rest optional.
+var InputFields = []string{"first", "last", "salary", "super", "period", "frequency", "scale", "help", "rate", "id"}
+
+// number of required input fields
+const requiredFields = 5
+
+// header names recognised for each input field when no column mapping is given for it (compared case-insensitively)
+var fieldAliases = map[string][]string{
+	"first":     {"first", "first name", "firstname", "given name"},
+	"last":      {"last", "last name", "lastname", "surname", "family name"},
+	"salary":    {"salary", "annual salary", "annualsalary"},
+	"super":     {"super", "super rate", "super rate (%)", "superrate"},
+	"period":    {"period", "pay period", "payment start date", "payment period"},
+	"frequency": {"frequency", "pay frequency"},
+	"scale":     {"scale", "tax scale", "residency"},
+	"help":      {"help", "study loan", "student loan"},
+	"rate":      {"rate", "hourly rate", "pay rate"},
+	"id":        {"id", "employee id", "employee number", "staff id"},
+}
+
+// ColumnMapping maps input field names to the header names used for them in an input file e.g. {"first": "Given Name"}
+type ColumnMapping map[string]string
+
+// Columns is the column mapping used by ReadPayrollRecords. Fields not mapped are found by their usual header names.
+var Columns ColumnMapping
+
+// Delimiter is the field separator used reading input and writing output CSV files
+var Delimiter = ','
+
+// ParseColumnMapping reads a column mapping of the form "field=Header Name,field=Header Name" e.g. "first=Given Name,salary=Base Pay"
+func ParseColumnMapping(s string) (ColumnMapping, error) {
+	return parseMapping(s, InputFields)
+}
+
+// parseMapping reads a mapping of field names to header names, checking each field is one of fields
+func parseMapping(s string, fields []string) (ColumnMapping, error) {
+	mapping := ColumnMapping{}
+
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		field := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("Column mapping <%s> must be of the form field=header", pair)
+		}
+
+		known := false
+		for _, f := range fields {
+			if field == f {
+				known = true
+			}
+		}
+
+		if !known {
+			return nil, fmt.Errorf("Unknown field <%s> in column mapping (fields: %s)", field, strings.Join(fields, ", "))
+		}
+
+		mapping[field] = strings.TrimSpace(kv[1])
+	}
+
+	return mapping, nil
+}
+
+// String formats a column mapping in the form accepted by ParseColumnMapping
+func (m ColumnMapping) String() string {
+	pairs := []string{}
+	for field, header := range m {
+		pairs = append(pairs, field+"="+header)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set parses a column mapping into m, allowing a ColumnMapping to be used directly as a command line flag
+func (m *ColumnMapping) Set(s string) error {
+	parsed, err := ParseColumnMapping(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// headerIndex checks whether row is a header row naming every required input field, and if so returns the column
+// index of each input field found in it. Fields named in mapping are matched by their mapped header name only.
+func headerIndex(row []string, mapping ColumnMapping) (map[string]int, bool) {
+	index := map[string]int{}
+
+	for _, field := range InputFields {
+		names := fieldAliases[field]
+		if header, ok := mapping[field]; ok {
+			names = []string{header}
+		}
+
+		for i, cell := range row {
+			for _, name := range names {
+				if strings.EqualFold(strings.TrimSpace(cell), name) {
+					index[field] = i
+				}
+			}
+		}
+	}
+
+	for _, field := range InputFields[:requiredFields] {
+		if _, ok := index[field]; !ok {
+			return nil, false
+		}
+	}
+
+	return index, true
+}
+
+// mapRow picks the input fields out of a row using a header index, returning them in InputFields order.
+// Optional fields missing from the row are left empty.
+func mapRow(row []string, index map[string]int) []string {
+	mapped := make([]string, len(InputFields))
+	for i, field := range InputFields {
+		if col, ok := index[field]; ok && col < len(row) {
+			mapped[i] = row[col]
+		}
+	}
+
+	return mapped
+}
+
+// columnIndex returns the column index of each of fields found in a header row (compared case-insensitively), which must name
+// at least the first required of them
+func columnIndex(header []string, fields []string, required int) (map[string]int, error) {
+	index := map[string]int{}
+	for i, cell := range header {
+		for _, field := range fields {
+			if strings.EqualFold(strings.TrimSpace(cell), field) {
+				index[field] = i
+			}
+		}
+	}
+
+	for _, field := range fields[:required] {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("No <%s> column in header row (columns: %s)", field, strings.Join(fields, ", "))
+		}
+	}
+
+	return index, nil
+}
+
+// cell returns the named field of a row read from a file with a header row, or "" if the file has no such column
+func cell(row []string, index map[string]int, name string) string {
+	if col, ok := index[name]; ok && col < len(row) {
+		return row[col]
+	}
+
+	return ""
+}
+
+// readLines reads a CSV file with a header row naming its columns (see columnIndex), calling each for every row after the
+// header with the row, the column index and the row's line number
+func readLines(inputFile string, fields []string, required int, each func(row []string, index map[string]int, line int)) error {
+	fileHandle, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer fileHandle.Close()
+
+	csvReader := csv.NewReader(fileHandle)
+	csvReader.Comma = Delimiter
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return fmt.Errorf("Error reading header row of <%s>: %v", inputFile, err)
+	}
+
+	index, err := columnIndex(header, fields, required)
+	if err != nil {
+		return fmt.Errorf("%v in <%s>", err, inputFile)
+	}
+
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, _ := csvReader.FieldPos(0)
+		each(row, index, line)
+	}
+}
+
+// ParseDelimiter reads a CSV delimiter: a single character, or "tab"
+func ParseDelimiter(s string) (rune, error) {
+	if strings.EqualFold(s, "tab") || s == "\\t" {
+		return '\t', nil
+	}
+
+	r := []rune(s)
+	if len(r) != 1 || r[0] == '"' || r[0] == '\r' || r[0] == '\n' {
+		return 0, fmt.Errorf("Invalid delimiter <%s>: must be a single character", s)
+	}
+
+	return r[0], nil
+}
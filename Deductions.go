@@ -0,0 +1,392 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"TaxBracket"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DeductionType is the kind of a deduction line
+type DeductionType int
+
+const (
+	SalarySacrifice DeductionType = iota // pre-tax deduction paid into the employee's super
+	PreTax                               // other pre-tax deduction e.g. a novated lease
+	PostTax                              // fixed amount taken from net pay e.g. union fees
+	Percent                              // percentage of net pay e.g. child support
+	Garnishee                            // court-ordered amount taken from net pay, leaving at least the protected earnings
+)
+
+// names of deduction types as given in a deductions file
+var deductionTypeNames = map[DeductionType]string{SalarySacrifice: "sacrifice", PreTax: "pre-tax", PostTax: "post-tax", Percent: "percent", Garnishee: "garnishee"}
+
+// ParseDeductionType reads a deduction type name e.g. "sacrifice"
+func ParseDeductionType(s string) (DeductionType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t, name := range deductionTypeNames {
+		if s == name {
+			return t, nil
+		}
+	}
+
+	return PreTax, fmt.Errorf("Unknown deduction type <%s>", s)
+}
+
+// String returns the name of the deduction type
+func (t DeductionType) String() string {
+	return deductionTypeNames[t]
+}
+
+// PreTaxType reports whether deductions of this type are taken before tax
+func (t DeductionType) PreTaxType() bool {
+	return t == SalarySacrifice || t == PreTax
+}
+
+// Deduction is a standing deduction from an employee's pay, read from a deductions file
+type Deduction struct {
+	Employee    string        // employee ID, or full name, of the employee the deduction is for
+	Type        DeductionType // kind of deduction
+	Description string        // what the deduction is for e.g. "Novated lease"
+	Amount      Money.Amount  // amount taken: a year's worth or each pay period's, as Annual says
+	Annual      bool          // whether Amount is a yearly amount, taken across the year's pay periods as salary is (pro-rated for a partial period)
+	Percent     Money.Rate    // percent: percentage of net pay taken
+	Priority    int           // order post-tax deductions are taken in, lowest first (deductions of equal priority in file order)
+	Protected   Money.Amount  // garnishee: protected earnings, the net pay each pay period the garnishee may not take (always per pay period)
+	Line        int           // line number of the deduction in the deductions file
+}
+
+// DeductionAmount is the amount of one deduction taken in a pay period
+type DeductionAmount struct {
+	Description string       `json:"description"`
+	Type        string       `json:"type"`
+	Amount      Money.Amount `json:"amount"`
+}
+
+// DeductionFields are the names of the columns of a deductions file, which must have a header row naming them (in any order).
+// The first three are required, the rest only needed by the types of deduction using them (see Deduction): an amount must say
+// in the per column whether it is a year's worth ("year") or each pay period's ("period").
+var DeductionFields = []string{"employee", "type", "description", "amount", "per", "percent", "priority", "protected"}
+
+// number of required deductions file columns
+const requiredDeductionFields = 3
+
+// ReadDeductions reads the deductions in a deductions file, keyed by employee (see normaliseKey), along with a report of the
+// problems found with invalid rows (which are left out) and any error encountered reading the file
+func ReadDeductions(inputFile string) (map[string][]*Deduction, ValidationReport, error) {
+	deductions := map[string][]*Deduction{}
+	report := ValidationReport{}
+
+	err := readLines(inputFile, DeductionFields, requiredDeductionFields, func(row []string, index map[string]int, line int) {
+		deduction, rowReport := createDeduction(row, index)
+		if len(rowReport) > 0 {
+			rowReport.locate(inputFile, line)
+			rowReport.about(deduction.Employee)
+			report = append(report, rowReport...)
+			return
+		}
+
+		deduction.Line = line
+		key := normaliseKey(deduction.Employee)
+		deductions[key] = append(deductions[key], deduction)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return deductions, report, nil
+}
+
+// createDeduction creates a deduction from a row of a deductions file, reporting any problems with its fields
+func createDeduction(row []string, index map[string]int) (*Deduction, ValidationReport) {
+	report := ValidationReport{}
+	field := func(name string) string { return cell(row, index, name) }
+
+	deduction := &Deduction{Employee: strings.TrimSpace(field("employee")), Description: strings.TrimSpace(field("description"))}
+	if deduction.Employee == "" {
+		report.add("employee", field("employee"), "employee name is required")
+	}
+
+	var err error
+	if deduction.Type, err = ParseDeductionType(field("type")); err != nil {
+		report.add("type", field("type"), "unknown deduction type (sacrifice, pre-tax, post-tax, percent or garnishee)")
+		return deduction, report
+	}
+
+	// percentage deductions are given by the percent field, all others by the amount field
+	if deduction.Type == Percent {
+		if deduction.Percent, err = Money.ParseRate(field("percent")); err != nil {
+			report.add("percent", field("percent"), "not a valid percentage")
+		} else if deduction.Percent <= 0 || deduction.Percent > 100*Money.RateScale {
+			report.add("percent", field("percent"), "percentage must be greater than 0% and no more than 100%")
+		}
+	} else if deduction.Amount, err = Money.Parse(field("amount")); err != nil {
+		report.add("amount", field("amount"), "not a valid money value")
+	} else if deduction.Amount <= 0 {
+		report.add("amount", field("amount"), "amount must be greater than zero")
+	}
+
+	if deduction.Type != Percent {
+		switch strings.ToLower(strings.TrimSpace(field("per"))) {
+		case "year", "annual", "yearly":
+			deduction.Annual = true
+		case "period", "pay period":
+			deduction.Annual = false
+		default:
+			report.add("per", field("per"), "say whether the amount is a year's worth (year) or each pay period's (period)")
+		}
+	}
+
+	if s := strings.TrimSpace(field("priority")); s != "" {
+		if deduction.Priority, err = strconv.Atoi(s); err != nil {
+			report.add("priority", field("priority"), "not a whole number")
+		}
+	}
+
+	if s := strings.TrimSpace(field("protected")); s != "" {
+		if deduction.Protected, err = Money.Parse(s); err != nil {
+			report.add("protected", field("protected"), "not a valid money value")
+		} else if deduction.Protected < 0 {
+			report.add("protected", field("protected"), "protected earnings must not be negative")
+		}
+	}
+
+	return deduction, report
+}
+
+// AttachDeductions gives each valid record the deductions read from deductionsFile for its employee, given by employee ID or by name.
+// Deductions for employees with no valid record, or by a name shared by several employees, are reported, as are records whose
+// pre-tax deductions this pay period add up to more than their gross pay - those records are marked invalid.
+func AttachDeductions(deductionsFile string, records []*PayrollRecord, deductions map[string][]*Deduction) ValidationReport {
+	report := ValidationReport{}
+	matched := map[string]bool{}
+	shared := sharedNames(records)
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		// deductions may be given by employee ID or by name, unless the name is shared
+		keys := []string{}
+		if rec.EmployeeID != "" {
+			keys = append(keys, normaliseKey(rec.EmployeeID))
+		}
+		if name := normaliseKey(rec.FullName()); !shared[name] {
+			keys = append(keys, name)
+		}
+
+		rec.Deductions = nil
+		for _, key := range keys {
+			rec.Deductions = append(rec.Deductions, deductions[key]...)
+			matched[key] = true
+		}
+		sort.SliceStable(rec.Deductions, func(i, j int) bool { return rec.Deductions[i].Line < rec.Deductions[j].Line })
+
+		// checked against this pay period's gross pay, as an hourly employee has no annual salary
+		if preTax, gross := rec.preTaxPeriod(), rec.GrossIncome(); preTax > gross {
+			first := rec.firstPreTax()
+			recReport := ValidationReport{&ValidationError{File: deductionsFile, Line: first.Line, Field: "amount", Value: first.Amount.String(),
+				Reason: fmt.Sprintf("pre-tax deductions for %s of %s this pay period are more than their gross pay of %s", first.Employee, preTax, gross)}}
+			rec.Valid = false
+			rec.ErrorStr = fmt.Sprintf("Invalid input record: %v", rec.Row)
+			rec.Errors = recReport
+			report = append(report, recReport...)
+		}
+	}
+
+	unmatched := ValidationReport{}
+	for key, list := range deductions {
+		if matched[key] {
+			continue
+		}
+
+		reason := "no valid payroll record for this employee"
+		if shared[key] {
+			reason = "more than one employee has this name: give their employee ID"
+		}
+
+		for _, deduction := range list {
+			unmatched = append(unmatched, &ValidationError{File: deductionsFile, Line: deduction.Line, Field: "employee", Value: deduction.Employee,
+				Reason: reason})
+		}
+	}
+
+	sort.Slice(unmatched, func(i, j int) bool { return unmatched[i].Line < unmatched[j].Line })
+	return append(report, unmatched...)
+}
+
+// preTaxAnnual returns the annual total of the record's pre-tax deductions, counting those taken each pay period as though
+// taken every pay period (see annualise)
+func (rec *PayrollRecord) preTaxAnnual() Money.Amount {
+	total, perPeriod := Money.Amount(0), Money.Amount(0)
+	for _, deduction := range rec.Deductions {
+		switch {
+		case !deduction.Type.PreTaxType():
+		case deduction.Annual:
+			total += deduction.Amount
+		default:
+			perPeriod += deduction.Amount
+		}
+	}
+
+	if perPeriod > 0 {
+		total += rec.annualise(perPeriod)
+	}
+
+	return total
+}
+
+// periodAmount returns the amount of a deduction taken this pay period: its own amount, or for a yearly amount its share
+// spread over the year's pay periods the same way salary is (see perPeriod)
+func (rec *PayrollRecord) periodAmount(deduction *Deduction) Money.Amount {
+	if deduction.Annual {
+		return rec.perPeriod(deduction.Amount.Exact()).Round(Rounding.Gross)
+	}
+
+	return deduction.Amount
+}
+
+// preTaxPeriod returns the total of the record's pre-tax deductions taken this pay period (see PreTaxDeductions)
+func (rec *PayrollRecord) preTaxPeriod() Money.Amount {
+	total := Money.Amount(0)
+	for _, deduction := range rec.PreTaxDeductions() {
+		total += deduction.Amount
+	}
+
+	return total
+}
+
+// firstPreTax returns the record's first pre-tax deduction, or nil if it has none
+func (rec *PayrollRecord) firstPreTax() *Deduction {
+	for _, deduction := range rec.Deductions {
+		if deduction.Type.PreTaxType() {
+			return deduction
+		}
+	}
+
+	return nil
+}
+
+// TaxableIncome returns the annual income this payroll record is taxed on: annual salary less pre-tax deductions, plus this pay period's
+// timesheet pay and taxable earnings lines annualised (taxed as though paid every pay period). Earnings lines withheld by the marginal
+// method are left out, and taxed on top of it (see marginal).
+func (rec *PayrollRecord) TaxableIncome() Money.Amount {
+	timesheet, _ := rec.timesheetPay()
+	annualised := rec.earningsTotal(func(e *Earnings) bool { return e.Taxable && e.Method == Annualised })
+	return rec.AnnualSalary - rec.preTaxAnnual() + rec.annualise(timesheet+annualised)
+}
+
+// calculate each pre-tax deduction taken this pay period (see periodAmount)
+func (rec *PayrollRecord) PreTaxDeductions() []DeductionAmount {
+	amounts := []DeductionAmount{}
+	for _, deduction := range rec.Deductions {
+		if deduction.Type.PreTaxType() {
+			amounts = append(amounts, DeductionAmount{deduction.Description, deduction.Type.String(), rec.periodAmount(deduction)})
+		}
+	}
+
+	return amounts
+}
+
+// calculate the salary sacrificed into super this pay period: the total of the record's salary sacrifice deductions
+func (rec *PayrollRecord) SalarySacrifice() Money.Amount {
+	total := Money.Amount(0)
+	for _, deduction := range rec.PreTaxDeductions() {
+		if deduction.Type == SalarySacrifice.String() {
+			total += deduction.Amount
+		}
+	}
+
+	return total
+}
+
+// calculate each post-tax deduction taken this pay period from net pay (see netPay), in priority order. Each deduction takes
+// no more than the net pay the ones before it left, and a garnishee no more than would leave its protected earnings: percentage
+// deductions are rounded as net income is.
+func (rec *PayrollRecord) PostTaxDeductions(table *TaxBracket.TaxTable) ([]DeductionAmount, error) {
+	net, err := rec.netPay(table)
+	if err != nil {
+		return nil, err
+	}
+
+	postTax := []*Deduction{}
+	for _, deduction := range rec.Deductions {
+		if !deduction.Type.PreTaxType() {
+			postTax = append(postTax, deduction)
+		}
+	}
+	sort.SliceStable(postTax, func(i, j int) bool { return postTax[i].Priority < postTax[j].Priority })
+
+	amounts := []DeductionAmount{}
+	remaining := net
+	for _, deduction := range postTax {
+		amount := rec.periodAmount(deduction)
+		limit := remaining
+
+		switch deduction.Type {
+		case Percent:
+			amount = net.Exact().MulRate(deduction.Percent).Round(Rounding.Net)
+		case Garnishee:
+			limit = remaining - deduction.Protected
+		}
+
+		if amount > limit {
+			amount = limit
+		}
+		if amount < 0 {
+			amount = 0
+		}
+
+		remaining -= amount
+		amounts = append(amounts, DeductionAmount{deduction.Description, deduction.Type.String(), amount})
+	}
+
+	return amounts, nil
+}
+
+// hasPreTaxDeductions reports whether any valid record has pre-tax deductions
+func hasPreTaxDeductions(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if rec.Valid && rec.preTaxAnnual() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasPostTaxDeductions reports whether any valid record has post-tax deductions
+func hasPostTaxDeductions(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		for _, deduction := range rec.Deductions {
+			if !deduction.Type.PreTaxType() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// totalOf returns the sum of a set of deduction amounts
+func totalOf(amounts []DeductionAmount) Money.Amount {
+	sum := Money.Amount(0)
+	for _, a := range amounts {
+		sum += a.Amount
+	}
+
+	return sum
+}
+
+// normaliseKey normalises an employee key for matching: lower case with runs of whitespace collapsed
+func normaliseKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
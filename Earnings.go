@@ -0,0 +1,298 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"fmt"
+	"strings"
+)
+
+// EarningsType is the kind of an earnings line
+type EarningsType int
+
+const (
+	Allowance EarningsType = iota // e.g. a shift or tool allowance
+	Bonus
+	Commission
+	Overtime
+	OtherEarnings
+)
+
+// names of earnings types as given in an earnings file
+var earningsTypeNames = map[EarningsType]string{Allowance: "allowance", Bonus: "bonus", Commission: "commission", Overtime: "overtime", OtherEarnings: "other"}
+
+// ParseEarningsType reads an earnings type name e.g. "bonus"
+func ParseEarningsType(s string) (EarningsType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t, name := range earningsTypeNames {
+		if s == name {
+			return t, nil
+		}
+	}
+
+	return OtherEarnings, fmt.Errorf("Unknown earnings type <%s>", s)
+}
+
+// String returns the name of the earnings type
+func (t EarningsType) String() string {
+	return earningsTypeNames[t]
+}
+
+// WithholdingMethod is how tax is withheld from a taxable earnings line
+type WithholdingMethod int
+
+const (
+	Annualised WithholdingMethod = iota // taxed as though paid every pay period, along with regular pay
+	Marginal                            // taxed at the margin: the extra annual tax it adds to annual income, withheld in full, e.g. for a bonus
+)
+
+// names of withholding methods as given in an earnings file
+var withholdingMethodNames = map[WithholdingMethod]string{Annualised: "annualised", Marginal: "marginal"}
+
+// ParseWithholdingMethod reads a withholding method name: "annualised" or "marginal"
+func ParseWithholdingMethod(s string) (WithholdingMethod, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for m, name := range withholdingMethodNames {
+		if s == name {
+			return m, nil
+		}
+	}
+
+	return Annualised, fmt.Errorf("Unknown withholding method <%s>", s)
+}
+
+// String returns the name of the withholding method
+func (m WithholdingMethod) String() string {
+	return withholdingMethodNames[m]
+}
+
+// Earnings is a line of pay on top of salary for one pay period, read from an earnings file
+type Earnings struct {
+	Employee    string            // employee ID, or full name, of the employee the earnings are for
+	Period      *PayPeriod        // pay period the earnings are paid in, or nil if the employee has only one record in the run
+	Type        EarningsType      // kind of earnings
+	Description string            // what the earnings are for e.g. "Night shift allowance"
+	Amount      Money.Amount      // amount paid: given, or hours times rate
+	Hours       Money.Quantity    // hours worked, if paid by the hour
+	Rate        Money.Amount      // hourly rate, if paid by the hour
+	Taxable     bool              // whether the earnings are taxed (the default)
+	Super       bool              // whether the earnings count towards super (ordinary time earnings): by default all but overtime do
+	Method      WithholdingMethod // how tax is withheld from taxable earnings: annualised unless given
+	Line        int               // line number of the earnings in the earnings file
+}
+
+// EarningsAmount is the amount of one earnings line paid in a pay period
+type EarningsAmount struct {
+	Description string       `json:"description"`
+	Type        string       `json:"type"`
+	Amount      Money.Amount `json:"amount"`
+	Taxable     bool         `json:"taxable"`
+	Super       bool         `json:"super"`
+	Method      string       `json:"method"` // tax withholding method, see WithholdingMethod
+}
+
+// EarningsFields are the names of the columns of an earnings file, which must have a header row naming them (in any order).
+// The first two are required; each line also needs either an amount, or hours and a rate.
+var EarningsFields = []string{"employee", "type", "description", "amount", "hours", "rate", "taxable", "super", "period", "method"}
+
+// number of required earnings file columns
+const requiredEarningsFields = 2
+
+// ReadEarnings reads the earnings lines in an earnings file in file order, along with a report of the problems found with invalid rows
+// (which are left out) and any error encountered reading the file
+func ReadEarnings(inputFile string) ([]*Earnings, ValidationReport, error) {
+	earnings := []*Earnings{}
+	report := ValidationReport{}
+
+	err := readLines(inputFile, EarningsFields, requiredEarningsFields, func(row []string, index map[string]int, line int) {
+		e, rowReport := createEarnings(row, index)
+		if len(rowReport) > 0 {
+			rowReport.locate(inputFile, line)
+			rowReport.about(e.Employee)
+			report = append(report, rowReport...)
+			return
+		}
+
+		e.Line = line
+		earnings = append(earnings, e)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return earnings, report, nil
+}
+
+// createEarnings creates an earnings line from a row of an earnings file, reporting any problems with its fields
+func createEarnings(row []string, index map[string]int) (*Earnings, ValidationReport) {
+	report := ValidationReport{}
+	field := func(name string) string { return cell(row, index, name) }
+
+	e := &Earnings{Employee: strings.TrimSpace(field("employee")), Description: strings.TrimSpace(field("description")), Taxable: true}
+	if e.Employee == "" {
+		report.add("employee", field("employee"), "employee name is required")
+	}
+
+	var err error
+	if e.Type, err = ParseEarningsType(field("type")); err != nil {
+		report.add("type", field("type"), "unknown earnings type (allowance, bonus, commission, overtime or other)")
+	}
+	e.Super = e.Type != Overtime
+
+	// earnings are either an amount, or hours at an hourly rate
+	if strings.TrimSpace(field("amount")) != "" {
+		if e.Amount, err = Money.Parse(field("amount")); err != nil {
+			report.add("amount", field("amount"), "not a valid money value")
+		} else if e.Amount <= 0 {
+			report.add("amount", field("amount"), "amount must be greater than zero")
+		}
+	} else {
+		var errHours, errRate error
+		e.Hours, errHours = Money.ParseQuantity(field("hours"))
+		e.Rate, errRate = Money.Parse(field("rate"))
+
+		switch {
+		case errHours != nil || e.Hours <= 0:
+			report.add("hours", field("hours"), "an amount, or a number of hours greater than zero and an hourly rate, is required")
+		case errRate != nil || e.Rate <= 0:
+			report.add("rate", field("rate"), "hourly rate must be a money value greater than zero")
+		default:
+			e.Amount = e.Rate.Exact().MulQuantity(e.Hours).Round(Money.Rounding{Precision: Money.Cents})
+		}
+	}
+
+	if s := field("taxable"); strings.TrimSpace(s) != "" {
+		if e.Taxable, err = parseYesNo(s); err != nil {
+			report.add("taxable", s, "must be yes or no")
+		}
+	}
+
+	if s := field("super"); strings.TrimSpace(s) != "" {
+		if e.Super, err = parseYesNo(s); err != nil {
+			report.add("super", s, "must be yes or no")
+		}
+	}
+
+	if s := strings.TrimSpace(field("period")); s != "" {
+		period, err := ParsePayPeriod(s, PeriodYear)
+		if err != nil {
+			report.add("period", s, err.Error())
+		}
+		e.Period = &period
+	}
+
+	if s := strings.TrimSpace(field("method")); s != "" {
+		if e.Method, err = ParseWithholdingMethod(s); err != nil {
+			report.add("method", s, "unknown withholding method (annualised or marginal)")
+		}
+	}
+
+	return e, report
+}
+
+// AttachEarnings adds each earnings line read from earningsFile to the valid record it is paid with: the record for its employee
+// (given by employee ID or by name) and pay period, or the employee's only record if it gives no pay period. Earnings lines matching
+// no record, or several, are reported.
+func AttachEarnings(earningsFile string, records []*PayrollRecord, earnings []*Earnings) ValidationReport {
+	report := ValidationReport{}
+	shared := sharedNames(records)
+
+	for _, e := range earnings {
+		if shared[normaliseKey(e.Employee)] {
+			report = append(report, &ValidationError{File: earningsFile, Line: e.Line, Field: "employee", Value: e.Employee,
+				Reason: "more than one employee has this name: give their employee ID"})
+			continue
+		}
+
+		matches := []*PayrollRecord{}
+		for _, rec := range records {
+			if rec.Valid && rec.isEmployee(e.Employee) && (e.Period == nil || samePeriod(rec.Period, *e.Period)) {
+				matches = append(matches, rec)
+			}
+		}
+
+		switch len(matches) {
+		case 1:
+			matches[0].Earnings = append(matches[0].Earnings, e)
+		case 0:
+			report = append(report, &ValidationError{File: earningsFile, Line: e.Line, Field: "employee", Value: e.Employee,
+				Reason: "no valid payroll record for this employee and pay period"})
+		default:
+			report = append(report, &ValidationError{File: earningsFile, Line: e.Line, Field: "period", Value: "",
+				Reason: fmt.Sprintf("%s has %d pay periods in the input: give the pay period the earnings are paid in", e.Employee, len(matches))})
+		}
+	}
+
+	return report
+}
+
+// samePeriod reports whether two pay periods cover the same dates
+func samePeriod(a, b PayPeriod) bool {
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+// calculate each earnings line paid this pay period
+func (rec *PayrollRecord) EarningsLines() []EarningsAmount {
+	amounts := []EarningsAmount{}
+	for _, e := range rec.Earnings {
+		amounts = append(amounts, EarningsAmount{e.Description, e.Type.String(), e.Amount, e.Taxable, e.Super, e.Method.String()})
+	}
+
+	return amounts
+}
+
+// earningsTotal returns the total of the record's earnings lines accepted by include
+func (rec *PayrollRecord) earningsTotal(include func(*Earnings) bool) Money.Amount {
+	total := Money.Amount(0)
+	for _, e := range rec.Earnings {
+		if include(e) {
+			total += e.Amount
+		}
+	}
+
+	return total
+}
+
+// marginal calculates the extra amount of an annual figure calculated from taxable income (e.g. the income tax or a levy on it)
+// due on the record's marginal earnings lines: the figure on taxable income plus those lines, less the figure on taxable income
+// alone. The whole of it is withheld this pay period, rounded as income tax is.
+func (rec *PayrollRecord) marginal(annual func(income Money.Amount) (Money.Exact, error)) (Money.Amount, error) {
+	extra := rec.earningsTotal(func(e *Earnings) bool { return e.Taxable && e.Method == Marginal })
+	if extra == 0 {
+		return 0, nil
+	}
+
+	income := rec.TaxableIncome()
+	with, err := annual(income + extra)
+	if err != nil {
+		return -1, err
+	}
+
+	without, err := annual(income)
+	if err != nil {
+		return -1, err
+	}
+
+	return with.Sub(without).Round(Rounding.Tax), nil
+}
+
+// annualise converts an amount for this record's pay period to an annual figure, the inverse of perPeriod
+func (rec *PayrollRecord) annualise(amount Money.Amount) Money.Amount {
+	num, den := rec.ProrationFactor()
+	if num == 0 {
+		num, den = 1, 1 // no working days in the period to pro-rate by
+	}
+
+	return amount.Exact().MulFrac(rec.Frequency.PeriodsPerYear()*den, num).Round(Money.Rounding{Precision: Money.Cents})
+}
+
+// hasEarnings reports whether any valid record has earnings lines
+func hasEarnings(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if rec.Valid && len(rec.Earnings) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package PayrollRecord
+
+import (
+	"TaxBracket"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format selects the format of the output file
+type Format int
+
+const (
+	CSV       Format = iota // one CSV row per valid record
+	JSON                    // a JSON array of OutputRecord
+	JSONLines               // one OutputRecord JSON object per line
+)
+
+// names of output formats as accepted by ParseFormat, which double as their file extensions
+var formatNames = map[Format]string{CSV: "csv", JSON: "json", JSONLines: "jsonl"}
+
+// OutputFormat is the format WriteOutputFile writes in. The zero value is CSV.
+var OutputFormat Format
+
+// OutputRecord is the JSON output schema for one input record: its payslip if valid, or the problems found with it if not
+type OutputRecord struct {
+	Line    int              `json:"line"`    // line number of the record in the input file
+	Valid   bool             `json:"valid"`   // whether the record was valid
+	Errors  ValidationReport `json:"errors"`  // problems found with an invalid record (empty if valid)
+	Payslip *Payslip         `json:"payslip"` // calculated figures for a valid record (null if invalid)
+}
+
+// ParseFormat reads an output format name: "csv", "json" or "jsonl"
+func ParseFormat(s string) (Format, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for f, name := range formatNames {
+		if s == name {
+			return f, nil
+		}
+	}
+
+	return CSV, fmt.Errorf("Unknown output format <%s>", s)
+}
+
+// String returns the name of the output format
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Set parses an output format into f, allowing a Format to be used directly as a command line flag
+func (f *Format) Set(s string) error {
+	parsed, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+
+	*f = parsed
+	return nil
+}
+
+// outputRecord builds the JSON output record for a payroll record
+func (rec *PayrollRecord) outputRecord(registry *TaxBracket.Registry) (*OutputRecord, error) {
+	out := &OutputRecord{Line: rec.Line, Valid: rec.Valid, Errors: rec.Errors}
+	if out.Errors == nil {
+		out.Errors = ValidationReport{}
+	}
+
+	if rec.Valid {
+		payslip, err := rec.Payslip(registry)
+		if err != nil {
+			return nil, err
+		}
+
+		out.Payslip = payslip
+	}
+
+	return out, nil
+}
+
+// writeJSON writes an OutputRecord for each record, valid or not, as a JSON array or as JSON Lines
+func writeJSON(w io.Writer, records []*PayrollRecord, registry *TaxBracket.Registry, lines bool) error {
+	out := []*OutputRecord{}
+	for _, rec := range records {
+		o, err := rec.outputRecord(registry)
+		if err != nil {
+			return err
+		}
+
+		out = append(out, o)
+	}
+
+	encoder := json.NewEncoder(w)
+
+	if lines {
+		for _, o := range out {
+			if err := encoder.Encode(o); err != nil {
+				return fmt.Errorf("Error writing JSON output: %v", err)
+			}
+		}
+
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("Error writing JSON output: %v", err)
+	}
+
+	return nil
+}
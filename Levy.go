@@ -0,0 +1,62 @@
+package TaxBracket
+
+import (
+	"Money"
+	"fmt"
+)
+
+// Levy is a flat-rate levy charged on top of bracket tax, such as the Medicare levy or a surcharge.
+// No levy is payable on an income at or below the threshold. Above it the levy is the percentage of the whole income,
+// except that while phasing in it is limited to the shade-in percentage of the income above the threshold.
+type Levy struct {
+	Name      string       `json:"name" yaml:"name"`           // name of the levy e.g. "Medicare Levy", also used as its output column header
+	Percent   Money.Rate   `json:"percent" yaml:"percent"`     // percentage of the whole income payable once fully phased in
+	Threshold Money.Amount `json:"threshold" yaml:"threshold"` // low-income threshold: no levy payable on incomes at or below it
+	ShadeIn   Money.Rate   `json:"shade_in" yaml:"shade_in"`   // percentage of income above the threshold the levy is limited to while phasing in (zero for none)
+}
+
+// AnnualLevy calculates the levy payable on an annual income
+func (levy *Levy) AnnualLevy(income Money.Amount) Money.Exact {
+	if income <= levy.Threshold {
+		return Money.Amount(0).Exact()
+	}
+
+	full := income.Exact().MulRate(levy.Percent)
+	if levy.ShadeIn == 0 {
+		return full
+	}
+
+	// the shade-in amount is the lower of the two while the levy phases in
+	shaded := (income - levy.Threshold).Exact().MulRate(levy.ShadeIn)
+	if shaded.Cmp(full) < 0 {
+		return shaded
+	}
+
+	return full
+}
+
+// checkLevies applies sanity checks to a table's levies
+func checkLevies(levies []*Levy) error {
+	names := map[string]bool{}
+
+	for i, levy := range levies {
+		if levy == nil || levy.Name == "" {
+			return fmt.Errorf("Levy %d has no name", i+1)
+		}
+
+		if names[levy.Name] {
+			return fmt.Errorf("Levy <%s> is defined more than once", levy.Name)
+		}
+		names[levy.Name] = true
+
+		if levy.Percent < 0 || levy.Percent > 100*Money.RateScale || levy.ShadeIn < 0 || levy.ShadeIn > 100*Money.RateScale {
+			return fmt.Errorf("Levy <%s> percentages must be between 0%% and 100%%", levy.Name)
+		}
+
+		if levy.Threshold < 0 {
+			return fmt.Errorf("Levy <%s> threshold must not be negative", levy.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,357 @@
+// Package Money provides a fixed-point monetary type so that payroll figures are calculated and summed exactly, without floating point drift.
+package Money
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Amount is a monetary value held as a whole number of cents
+type Amount int64
+
+// Rate is a percentage held as a whole number of ten-thousandths of a percent (e.g. 9.5% -> 95000)
+type Rate int64
+
+// RateScale is the number of Rate units in one percent
+const RateScale = 10000
+
+// Quantity is a non-monetary decimal quantity such as hours worked or a pay multiplier, held as a whole number of hundredths (e.g. 7.5 -> 750)
+type Quantity int64
+
+// QuantityScale is the number of Quantity units in one
+const QuantityScale = 100
+
+// Exact is an intermediate calculation result in cents, held as an exact fraction until it is rounded back to an Amount
+type Exact struct {
+	r *big.Rat
+}
+
+// Parse reads a decimal dollar value such as "60050", "60050.5" or "$1,234.56" into an Amount. More than two decimal places is an error.
+func Parse(s string) (Amount, error) {
+	n, err := parseDecimal(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid money value <%s>", s)
+	}
+
+	return Amount(n), nil
+}
+
+// ParseRate reads a percentage such as "9", "9.5" or "9.5%" into a Rate. More than four decimal places is an error.
+func ParseRate(s string) (Rate, error) {
+	n, err := parseDecimal(strings.TrimSuffix(strings.TrimSpace(s), "%"), 4)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid percentage value <%s>", s)
+	}
+
+	return Rate(n), nil
+}
+
+// ParseQuantity reads a decimal quantity such as "38", "7.5" or "1.25" into a Quantity. More than two decimal places is an error.
+func ParseQuantity(s string) (Quantity, error) {
+	n, err := parseDecimal(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid quantity <%s>", s)
+	}
+
+	return Quantity(n), nil
+}
+
+// parseDecimal reads a decimal string and returns it scaled up by 10^places as an integer
+func parseDecimal(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "$")
+	s = strings.Replace(s, ",", "", -1) // allow thousands separators e.g. 1,234.56
+
+	neg := false
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+
+	if (whole == "" && frac == "") || len(frac) > places {
+		return 0, fmt.Errorf("invalid decimal <%s>", s)
+	}
+
+	// pad fractional digits out to the required number of places and read the whole thing as one integer
+	digits := whole + frac + strings.Repeat("0", places-len(frac))
+
+	var n int64
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid decimal <%s>", s)
+		}
+
+		if n > (1<<62)/10 {
+			return 0, fmt.Errorf("decimal out of range <%s>", s)
+		}
+
+		n = n*10 + int64(c-'0')
+	}
+
+	if neg {
+		n = -n
+	}
+
+	return n, nil
+}
+
+// FromDollars returns an Amount for a whole number of dollars
+func FromDollars(d int64) Amount {
+	return Amount(d * 100)
+}
+
+// String formats an Amount as a plain decimal with two places e.g. "5004.00" or "-12.50"
+func (a Amount) String() string {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// String formats a Rate as a percentage with trailing zeros removed e.g. "9.5%"
+func (r Rate) String() string {
+	sign := ""
+	n := int64(r)
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	s := fmt.Sprintf("%s%d", sign, n/RateScale)
+	if frac := n % RateScale; frac != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%04d", frac), "0")
+	}
+
+	return s + "%"
+}
+
+// String formats a Quantity as a decimal with trailing zeros removed e.g. "7.5"
+func (q Quantity) String() string {
+	return strings.TrimSuffix(Rate(int64(q)*(RateScale/QuantityScale)).String(), "%")
+}
+
+// MarshalJSON writes a Quantity as a JSON number e.g. 7.5
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// MarshalJSON writes an Amount as an exact JSON number with two decimal places e.g. 5004.00
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads an Amount from a JSON number or string e.g. 5004.5 or "5004.50". A JSON null reads as zero, as for
+// a field left out e.g. the topmost tax bracket's "upper": null.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*a = 0
+		return nil
+	}
+
+	return a.UnmarshalText([]byte(strings.Trim(string(b), `"`)))
+}
+
+// UnmarshalText reads an Amount from text as accepted by Parse, allowing Amounts to be read from configuration files
+func (a *Amount) UnmarshalText(b []byte) error {
+	parsed, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
+
+// MarshalJSON writes a Rate as a JSON number of percent e.g. 9.5
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(strings.TrimSuffix(r.String(), "%")), nil
+}
+
+// UnmarshalJSON reads a Rate from a JSON number or string of percent e.g. 9.5 or "9.5%". A JSON null reads as zero.
+func (r *Rate) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*r = 0
+		return nil
+	}
+
+	return r.UnmarshalText([]byte(strings.Trim(string(b), `"`)))
+}
+
+// UnmarshalText reads a Rate from text as accepted by ParseRate, allowing Rates to be read from configuration files
+func (r *Rate) UnmarshalText(b []byte) error {
+	parsed, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Exact converts an Amount into an exact intermediate value for further calculation
+func (a Amount) Exact() Exact {
+	return Exact{new(big.Rat).SetInt64(int64(a))}
+}
+
+// Add returns e + o
+func (e Exact) Add(o Exact) Exact {
+	return Exact{new(big.Rat).Add(e.r, o.r)}
+}
+
+// Sub returns e - o
+func (e Exact) Sub(o Exact) Exact {
+	return Exact{new(big.Rat).Sub(e.r, o.r)}
+}
+
+// MulRate returns e multiplied by the percentage r (e.g. e * 9.5 / 100)
+func (e Exact) MulRate(r Rate) Exact {
+	return Exact{new(big.Rat).Mul(e.r, big.NewRat(int64(r), 100*RateScale))}
+}
+
+// MulQuantity returns e multiplied by the quantity q (e.g. an hourly rate times 7.5 hours)
+func (e Exact) MulQuantity(q Quantity) Exact {
+	return Exact{new(big.Rat).Mul(e.r, big.NewRat(int64(q), QuantityScale))}
+}
+
+// Div returns e divided by n
+func (e Exact) Div(n int64) Exact {
+	return Exact{new(big.Rat).Quo(e.r, big.NewRat(n, 1))}
+}
+
+// MulFrac returns e multiplied by the fraction num/den
+func (e Exact) MulFrac(num, den int64) Exact {
+	return Exact{new(big.Rat).Mul(e.r, big.NewRat(num, den))}
+}
+
+// Cmp compares e and o, returning -1 if e < o, 0 if e == o and +1 if e > o
+func (e Exact) Cmp(o Exact) int {
+	return e.r.Cmp(o.r)
+}
+
+// RoundingMode selects which way a value lying between two rounding units is moved
+type RoundingMode int
+
+const (
+	HalfUp   RoundingMode = iota // round to nearest, halves up (if >= .50 round up, else round down)
+	HalfEven                     // round to nearest, halves to the even unit (banker's rounding)
+	Floor                        // round towards negative infinity
+	Ceiling                      // round towards positive infinity
+	Truncate                     // round towards zero
+)
+
+// Precision selects the unit a value is rounded to
+type Precision int
+
+const (
+	Dollars Precision = iota // round to whole dollars
+	Cents                    // round to whole cents
+)
+
+// Rounding is a rounding policy: a mode and the unit to round to. The zero value rounds half-up to whole dollars.
+type Rounding struct {
+	Mode      RoundingMode
+	Precision Precision
+}
+
+// names of rounding modes and precisions as accepted by ParseRounding
+var modeNames = map[RoundingMode]string{HalfUp: "half-up", HalfEven: "half-even", Floor: "floor", Ceiling: "ceiling", Truncate: "truncate"}
+var precisionNames = map[Precision]string{Dollars: "dollars", Cents: "cents"}
+
+// ParseRounding reads a rounding policy of the form "mode[:precision]" e.g. "half-even:cents" or "floor".
+// Precision defaults to whole dollars if not given.
+func ParseRounding(s string) (Rounding, error) {
+	var r Rounding
+
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(s)), ":", 2)
+
+	found := false
+	for mode, name := range modeNames {
+		if parts[0] == name {
+			r.Mode = mode
+			found = true
+		}
+	}
+
+	if !found {
+		return r, fmt.Errorf("Unknown rounding mode <%s>", parts[0])
+	}
+
+	if len(parts) == 2 {
+		found = false
+		for prec, name := range precisionNames {
+			if parts[1] == name {
+				r.Precision = prec
+				found = true
+			}
+		}
+
+		if !found {
+			return r, fmt.Errorf("Unknown rounding precision <%s>", parts[1])
+		}
+	}
+
+	return r, nil
+}
+
+// String formats a rounding policy in the form accepted by ParseRounding
+func (r Rounding) String() string {
+	return modeNames[r.Mode] + ":" + precisionNames[r.Precision]
+}
+
+// Set parses a rounding policy into r, allowing a Rounding to be used directly as a command line flag
+func (r *Rounding) Set(s string) error {
+	parsed, err := ParseRounding(s)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+	return nil
+}
+
+// Round converts an exact value back to an Amount according to rounding policy r
+func (e Exact) Round(r Rounding) Amount {
+	unit := int64(100) // cents per rounding unit
+	if r.Precision == Cents {
+		unit = 1
+	}
+
+	units := new(big.Rat).Quo(e.r, big.NewRat(unit, 1))
+
+	// floor of the unit value: big.Int.Div rounds towards negative infinity for a positive divisor
+	fl := new(big.Int).Div(units.Num(), units.Denom())
+	rem := new(big.Rat).Sub(units, new(big.Rat).SetInt(fl)) // fractional part, 0 <= rem < 1
+
+	up := false // whether to move up from the floor to the next unit
+	if rem.Sign() != 0 {
+		switch r.Mode {
+		case HalfUp:
+			up = rem.Cmp(big.NewRat(1, 2)) >= 0
+		case HalfEven:
+			c := rem.Cmp(big.NewRat(1, 2))
+			up = c > 0 || (c == 0 && fl.Bit(0) == 1)
+		case Floor:
+			up = false
+		case Ceiling:
+			up = true
+		case Truncate:
+			up = fl.Sign() < 0 // the floor of a negative value is further from zero
+		}
+	}
+
+	if up {
+		fl.Add(fl, big.NewInt(1))
+	}
+
+	return Amount(fl.Int64() * unit)
+}
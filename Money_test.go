@@ -0,0 +1,94 @@
+// Go tests are placed in files with the pattern *_test.go. Test Methods have the signature func <TestMethod>(t *testing.T) The tests are run with "go test" command.
+
+package Money
+
+import (
+	"testing"
+)
+
+// tests for Exact.Round() routine (round to whole dollars: >= .50 rounds up)
+func TestRound(t *testing.T) {
+	var tests = []struct {
+		input Amount
+		want  Amount
+	}{
+		{0, 0},
+		{30, 0},
+		{50, 100},
+		{60, 100},
+		{1030, 1000},
+		{1050, 1100},
+		{1080, 1100},
+	}
+
+	for _, test := range tests {
+		if got := test.input.Exact().Round(Rounding{}); got != test.want {
+			t.Errorf("FAILED: Round(%s) = %s", test.input, got)
+		}
+	}
+}
+
+// tests for Exact.Round() with each rounding policy
+func TestRoundingPolicies(t *testing.T) {
+	var tests = []struct {
+		policy string
+		input  Amount
+		div    int64
+		want   Amount
+	}{
+		{"half-up", 1050, 1, 1100},
+		{"half-even", 1050, 1, 1000},
+		{"half-even", 1150, 1, 1200},
+		{"floor", 1099, 1, 1000},
+		{"ceiling", 1001, 1, 1100},
+		{"truncate", -1099, 1, -1000},
+		{"half-up:cents", 1001, 2, 501},
+		{"half-even:cents", 1001, 2, 500},
+		{"floor:cents", 1000, 3, 333},
+		{"ceiling:cents", 1000, 3, 334},
+	}
+
+	for _, test := range tests {
+		policy, err := ParseRounding(test.policy)
+		if err != nil {
+			t.Errorf("FAILED: ParseRounding(%s): %v", test.policy, err)
+			continue
+		}
+
+		if got := test.input.Exact().Div(test.div).Round(policy); got != test.want {
+			t.Errorf("FAILED: Round(%s / %d, %s) = %s: expected <%s>", test.input, test.div, test.policy, got, test.want)
+		}
+	}
+
+	if _, err := ParseRounding("sideways"); err == nil {
+		t.Errorf("FAILED: ParseRounding(sideways) should return an error")
+	}
+}
+
+// tests for Parse() and ParseRate()
+func TestParseMoney(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  Amount
+		valid bool
+	}{
+		{"60050", 6005000, true},
+		{" 60050.5", 6005050, true},
+		{"$1,234.56", 123456, true},
+		{"0.07", 7, true},
+		{"1.234", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.input)
+		if (err == nil) != test.valid || got != test.want {
+			t.Errorf("FAILED: Parse(%q) = %s, %v", test.input, got, err)
+		}
+	}
+
+	if r, err := ParseRate("9.5%"); err != nil || r != 95000 || r.String() != "9.5%" {
+		t.Errorf("FAILED: ParseRate(9.5%%) = %s, %v", r, err)
+	}
+}
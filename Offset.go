@@ -0,0 +1,89 @@
+package TaxBracket
+
+import (
+	"Money"
+	"fmt"
+)
+
+// Offset is a tax offset reducing the tax payable on an income, such as the low income tax offset.
+// The full amount is reduced by each phase-out range the income reaches, by the range's percentage of the income within it,
+// until nothing is left.
+type Offset struct {
+	Name     string       `json:"name" yaml:"name"`           // name of the offset e.g. "LITO", also used as its output column header
+	Amount   Money.Amount `json:"amount" yaml:"amount"`       // full annual offset, before any phase-out
+	PhaseOut []*PhaseOut  `json:"phase_out" yaml:"phase_out"` // income ranges over which the offset is reduced, in order
+}
+
+// PhaseOut is an income range over which an offset is reduced by a percentage of the income within it
+type PhaseOut struct {
+	From    Money.Amount `json:"from" yaml:"from"`       // income above which the reduction starts
+	To      Money.Amount `json:"to" yaml:"to"`           // income at which the reduction stops (zero for no limit)
+	Percent Money.Rate   `json:"percent" yaml:"percent"` // percentage of the income between From and To the offset is reduced by
+}
+
+// AnnualOffset calculates the offset an annual income is entitled to, never less than zero
+func (offset *Offset) AnnualOffset(income Money.Amount) Money.Exact {
+	amount := offset.Amount.Exact()
+
+	for _, phase := range offset.PhaseOut {
+		if income <= phase.From {
+			continue
+		}
+
+		within := income
+		if phase.To != 0 && within > phase.To {
+			within = phase.To
+		}
+
+		amount = amount.Sub((within - phase.From).Exact().MulRate(phase.Percent))
+	}
+
+	if amount.Cmp(Money.Amount(0).Exact()) < 0 {
+		return Money.Amount(0).Exact()
+	}
+
+	return amount
+}
+
+// checkOffsets applies sanity checks to a table's offsets
+func checkOffsets(offsets []*Offset) error {
+	names := map[string]bool{}
+
+	for i, offset := range offsets {
+		if offset == nil || offset.Name == "" {
+			return fmt.Errorf("Offset %d has no name", i+1)
+		}
+
+		if names[offset.Name] {
+			return fmt.Errorf("Offset <%s> is defined more than once", offset.Name)
+		}
+		names[offset.Name] = true
+
+		if offset.Amount < 0 {
+			return fmt.Errorf("Offset <%s> amount must not be negative", offset.Name)
+		}
+
+		prevTo := Money.Amount(0)
+		for j, phase := range offset.PhaseOut {
+			if phase == nil {
+				return fmt.Errorf("Offset <%s> phase-out %d is empty", offset.Name, j+1)
+			}
+
+			if phase.From < prevTo || (phase.To != 0 && phase.To <= phase.From) {
+				return fmt.Errorf("Offset <%s> phase-out %d must cover a range of income above the one before it", offset.Name, j+1)
+			}
+
+			if phase.To == 0 && j != len(offset.PhaseOut)-1 {
+				return fmt.Errorf("Only the last phase-out of offset <%s> may have no upper limit", offset.Name)
+			}
+
+			if phase.Percent < 0 || phase.Percent > 100*Money.RateScale {
+				return fmt.Errorf("Offset <%s> phase-out percentages must be between 0%% and 100%%", offset.Name)
+			}
+
+			prevTo = phase.To
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package PayrollRecord
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Overwrite allows output files that already exist to be replaced. By default writing to an existing file is refused.
+var Overwrite bool
+
+// writeFile writes a file through the write function, or to standard output if fileName is "-".
+// The file is written to a temporary file in the same directory and renamed into place once complete, so that a
+// failed run never leaves a partly written file. An existing file is only replaced if Overwrite is set.
+func writeFile(fileName string, write func(w io.Writer) error) error {
+	if fileName == "-" {
+		return write(os.Stdout)
+	}
+
+	if _, err := os.Stat(fileName); err == nil && !Overwrite {
+		return fmt.Errorf("Output file <%s> already exists (use -force to overwrite)", fileName)
+	}
+
+	return replaceFile(fileName, write)
+}
+
+// removeFile removes an output file left by an earlier run, if there is one. As with writeFile, an existing file is only
+// removed if Overwrite is set.
+func removeFile(fileName string) error {
+	if fileName == "-" {
+		return nil
+	}
+
+	if _, err := os.Stat(fileName); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if !Overwrite {
+		return fmt.Errorf("Output file <%s> already exists (use -force to overwrite)", fileName)
+	}
+
+	if err := os.Remove(fileName); err != nil {
+		return fmt.Errorf("Error removing output file <%s>: %v", fileName, err)
+	}
+
+	return nil
+}
+
+// replaceFile writes a file through the write function via a temporary file renamed into place, replacing any existing file
+func replaceFile(fileName string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), "."+filepath.Base(fileName)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("Error creating output file <%s>: %v", fileName, err)
+	}
+	defer os.Remove(tmp.Name()) // clean up the temporary file if it isn't renamed into place
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Error writing output file <%s>: %v", fileName, err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("Error writing output file <%s>: %v", fileName, err)
+	}
+
+	if err := os.Rename(tmp.Name(), fileName); err != nil {
+		return fmt.Errorf("Error writing output file <%s>: %v", fileName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package PayrollRecord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PayFrequency is how often an employee is paid. The zero value is Monthly.
+type PayFrequency int
+
+const (
+	Monthly PayFrequency = iota
+	Weekly
+	Fortnightly
+	SemiMonthly // twice a month: the 1st to the 15th and the 16th to the end of the month
+	Quarterly
+)
+
+// names of pay frequencies as accepted by ParsePayFrequency
+var frequencyNames = map[PayFrequency]string{Monthly: "monthly", Weekly: "weekly", Fortnightly: "fortnightly", SemiMonthly: "semi-monthly", Quarterly: "quarterly"}
+
+// number of pay periods in a year for each frequency
+var periodsPerYear = map[PayFrequency]int64{Monthly: 12, Weekly: 52, Fortnightly: 26, SemiMonthly: 24, Quarterly: 4}
+
+// ParsePayFrequency reads a pay frequency name e.g. "fortnightly"
+func ParsePayFrequency(s string) (PayFrequency, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for f, name := range frequencyNames {
+		if s == name {
+			return f, nil
+		}
+	}
+
+	return Monthly, fmt.Errorf("Unknown pay frequency <%s>", s)
+}
+
+// DeriveFrequency works out the pay frequency from the dates a pay period covers. A period within one calendar month is monthly,
+// pro-rated if it is only part of the month (see Proration), and a full calendar quarter is quarterly. The 1st-15th and 16th-end of
+// a month are full semi-monthly periods as much as partial monthly ones, so as with any other period their frequency must be given.
+func DeriveFrequency(p PayPeriod) (PayFrequency, error) {
+	fullMonth := p.Start.Day() == 1 && p.End.Day() == daysInMonth(p.End)
+	sameYear := p.Start.Year() == p.End.Year()
+	sameMonth := sameYear && p.Start.Month() == p.End.Month()
+	semiMonthly := (p.Start.Day() == 1 && p.End.Day() == 15) || (p.Start.Day() == 16 && p.End.Day() == daysInMonth(p.End))
+
+	switch {
+	case sameMonth && !semiMonthly:
+		return Monthly, nil
+	case fullMonth && sameYear && (p.Start.Month()-1)%3 == 0 && p.End.Month() == p.Start.Month()+2:
+		return Quarterly, nil
+	}
+
+	return Monthly, fmt.Errorf("Unable to work out pay frequency for pay period <%s>", p)
+}
+
+// daysInMonth returns the number of days in the month of date d
+func daysInMonth(d time.Time) int {
+	return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// PeriodsPerYear returns the number of pay periods in a year at this frequency
+func (f PayFrequency) PeriodsPerYear() int64 {
+	return periodsPerYear[f]
+}
+
+// String returns the name of the pay frequency
+func (f PayFrequency) String() string {
+	return frequencyNames[f]
+}
@@ -0,0 +1,136 @@
+package PayrollRecord
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// PayPeriod is the span of dates covered by a payment, parsed from the pay period field of an input record
+type PayPeriod struct {
+	Start time.Time // first day of the period
+	End   time.Time // last day of the period (inclusive)
+	Text  string    // pay period as given in the input record e.g. "01 March – 31 March"
+}
+
+// PeriodYear is the year assumed for pay periods given without one e.g. "01 March – 31 March". There is no default (zero):
+// the year decides the pay period's dates and the tax table in force, so it must be given for the same input to give the same figures.
+var PeriodYear int
+
+// date layouts accepted for either end of a pay period, with and without a year
+var periodLayoutsWithYear = []string{"2006-1-2", "2 January 2006", "2 Jan 2006", "January 2 2006", "Jan 2 2006"}
+var periodLayoutsNoYear = []string{"2 January", "2 Jan", "January 2", "Jan 2"}
+
+// ISO date ranges are matched whole, since their dates contain the hyphen that text dates use as a separator
+var isoPeriod = regexp.MustCompile(`^(\d{4}-\d{1,2}-\d{1,2})\s*(?:–|—|-|/|to)\s*(\d{4}-\d{1,2}-\d{1,2})$`)
+var periodSeparator = regexp.MustCompile(`\s*(?:–|—|-|\bto\b)\s*`)
+
+// ParsePayPeriod reads a pay period such as "01 March – 31 March", "1 Mar 2024 - 31 Mar 2024" or "2024-03-01 – 2024-03-31".
+// Where neither date has a year, year is used (an error if it is zero); where only one does, it applies to both. A period ending before it starts
+// without an explicit end year is taken to run into the next year (e.g. "16 December – 15 January").
+func ParsePayPeriod(s string, year int) (PayPeriod, error) {
+	period := PayPeriod{Text: strings.TrimSpace(s)}
+
+	// split the period into its start and end dates
+	var parts []string
+	if m := isoPeriod.FindStringSubmatch(period.Text); m != nil {
+		parts = m[1:]
+	} else {
+		parts = periodSeparator.Split(period.Text, -1)
+	}
+
+	if len(parts) != 2 {
+		return period, fmt.Errorf("Pay period <%s> must be a start and end date separated by a dash", s)
+	}
+
+	start, startHasYear, err := parsePeriodDate(parts[0])
+	if err != nil {
+		return period, err
+	}
+
+	end, endHasYear, err := parsePeriodDate(parts[1])
+	if err != nil {
+		return period, err
+	}
+
+	// fill in any missing year
+	switch {
+	case !startHasYear && !endHasYear && year == 0:
+		return period, fmt.Errorf("Pay period <%s> has no year: give one, or the year to assume (-year)", s)
+	case !startHasYear && !endHasYear:
+		start, err = withYear(start, year)
+		if err == nil {
+			end, err = withYear(end, year)
+		}
+		if err == nil && end.Before(start) {
+			end, err = withYear(end, year+1)
+		}
+	case !startHasYear:
+		start, err = withYear(start, end.Year())
+		if err == nil && end.Before(start) {
+			start, err = withYear(start, end.Year()-1)
+		}
+	case !endHasYear:
+		end, err = withYear(end, start.Year())
+		if err == nil && end.Before(start) {
+			end, err = withYear(end, start.Year()+1)
+		}
+	}
+
+	if err != nil {
+		return period, fmt.Errorf("Pay period <%s>: %v", s, err)
+	}
+
+	// sanity check the period: it must run forwards and cover no more than a year
+	if end.Before(start) {
+		return period, fmt.Errorf("Pay period <%s> ends before it starts", s)
+	}
+
+	if !end.Before(start.AddDate(1, 0, 0)) {
+		return period, fmt.Errorf("Pay period <%s> is longer than a year", s)
+	}
+
+	period.Start = start
+	period.End = end
+	return period, nil
+}
+
+// parsePeriodDate reads one end of a pay period, reporting whether a year was given
+func parsePeriodDate(s string) (time.Time, bool, error) {
+	s = strings.Join(strings.Fields(strings.Replace(s, ",", " ", -1)), " ") // normalise whitespace and drop commas e.g. "March 1, 2024"
+
+	for _, layout := range periodLayoutsWithYear {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, true, nil
+		}
+	}
+
+	for _, layout := range periodLayoutsNoYear {
+		if d, err := time.Parse(layout, s); err == nil {
+			return d, false, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("Invalid pay period date <%s>", s)
+}
+
+// withYear returns date d moved into year y, failing if the day does not exist in that year (29 February)
+func withYear(d time.Time, y int) (time.Time, error) {
+	moved := time.Date(y, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	if moved.Day() != d.Day() {
+		return d, fmt.Errorf("%s %d is not a valid date", d.Format("2 January"), y)
+	}
+
+	return moved, nil
+}
+
+// Days returns the number of calendar days covered by the period, counting both the start and end day
+func (p PayPeriod) Days() int {
+	return int(p.End.Sub(p.Start).Hours()/24) + 1
+}
+
+// String returns the pay period as given in the input record
+func (p PayPeriod) String() string {
+	return p.Text
+}
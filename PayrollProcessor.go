@@ -1,46 +1,223 @@
 // PayrollProcessor reads in a set of payroll records from an input CSV file and writes processed data per requirements onto an output CSV file.
 // The code uses internal struct PayrollRecord to model input records and implemets a number of associated methods to that type in order to
-// calculate output parameters. Tax bracket information is stored in a configuration file, TAX_CONFIG.csv. The tax bracket information is managed
+// calculate output parameters. Tax bracket information is stored in a configuration file, e.g. TAX_CONFIG.csv (or a JSON/YAML tax table). The tax bracket information is managed
 // by a separate custom package, TaxBracket. It reads the tax bracket data and makes it available within the program as required.
 
 package main
 
 // import required external pakages
 import (
+	"Money"         // custom package providing fixed-point monetary values and rounding policies
 	"PayrollRecord" // custom package providing functionality to manage payroll input records
 	"TaxBracket"    // custom package provides functionality to read external tax bracket connfiguration
+	"flag"
 	"fmt"
 	"os"
 )
 
 // ------------ main method ----------------
 func main() {
+	// command line options: rounding policies may be given for all figures at once and/or per calculated figure
+	roundAll := flag.String("round", "", "rounding policy for all figures, as mode[:precision] (modes: half-up, half-even, floor, ceiling, truncate; precisions: dollars, cents)")
+	flag.Var(&PayrollRecord.Rounding.Gross, "round-gross", "rounding policy for gross income (default half-up:dollars)")
+	flag.Var(&PayrollRecord.Rounding.Tax, "round-tax", "rounding policy for income tax (default half-up:dollars)")
+	flag.Var(&PayrollRecord.Rounding.Net, "round-net", "rounding policy for net income (default half-up:dollars)")
+	flag.Var(&PayrollRecord.Rounding.Super, "round-super", "rounding policy for super (default half-up:dollars)")
+	flag.IntVar(&PayrollRecord.PeriodYear, "year", 0, "year assumed for pay periods given without one e.g. \"01 March – 31 March\" (default none: such periods are rejected)")
+	flag.Var(&PayrollRecord.Proration, "prorate", "pro-ration method for partial pay periods: calendar or working (default calendar)")
+	flag.Var(&PayrollRecord.Columns, "columns", "input column mapping by header name, as field=header pairs e.g. \"first=Given Name,salary=Base Pay\" (fields: first, last, salary, super, period, frequency, scale, help, rate, id)")
+	flag.BoolVar(&PayrollRecord.OutputHeader, "header", true, "write a header row to the output file")
+	flag.Func("output-columns", "output header names, as field=header pairs e.g. \"gross=Gross Pay\" (fields: id, name, period, gross, tax, net, super)", func(s string) (err error) {
+		PayrollRecord.OutputHeaders, err = PayrollRecord.ParseOutputColumns(s)
+		return err
+	})
+	flag.Func("delimiter", "field separator for input and output CSV files: a single character or \"tab\" (default \",\")", func(s string) (err error) {
+		PayrollRecord.Delimiter, err = PayrollRecord.ParseDelimiter(s)
+		return err
+	})
+	flag.Var(&PayrollRecord.OutputFormat, "format", "output format: csv, json or jsonl (default csv)")
+	earningsFile := flag.String("earnings", "", "file of earnings paid on top of salary, with a header row naming the columns employee, type, description, amount (or hours and rate), taxable, super, period and method (annualised or marginal tax withholding)")
+	timesheetFile := flag.String("timesheet", "", "file of hours worked by hourly employees, with a header row naming the columns employee, date (YYYY-MM-DD), hours and optionally rate")
+	flag.Var(&PayrollRecord.Penalties, "penalties", "penalty rates for timesheet hours, as day=multiplier pairs e.g. \"saturday=1.25,sunday=1.5,holiday=2.5\"")
+	holidaysFile := flag.String("holidays", "", "file of public holidays paid at the holiday penalty rate, with a header row naming the columns date (YYYY-MM-DD) and optionally name")
+	deductionsFile := flag.String("deductions", "", "file of standing deductions from employees' pay, with a header row naming the columns employee, type, description, amount, per (year or period), percent, priority and protected")
+	outFile := flag.String("o", "", "output file, or \"-\" for standard output (default <inputfile>-out.<format>)")
+	rejectsFile := flag.String("rejects", "", "file to write rejected input records to (default <inputfile>-rejects.csv)")
+	flag.Var(&PayrollRecord.SuperCap, "super-cap", "what to do when super would go over the concessional contributions cap: warn, or cap to limit the employer's contribution (default warn)")
+	flag.BoolVar(&PayrollRecord.Overwrite, "force", false, "overwrite output files that already exist")
+	ytdFile := flag.String("ytd", "", "year-to-date store (a JSON file, created if it doesn't exist) to carry each employee's totals between runs and output YTD columns from")
+	finalise := flag.Bool("finalise", false, "post this run's totals to the -ytd store once the output file is written")
+	flag.Parse()
+
 	// if inputfiles aren't provided on command line, show usage message and abort
-	if len(os.Args) <= 2 {
-		fmt.Println("Usage: > go run PayrollProcessor.go <inputfile> <taxconfigfile>")
+	if flag.NArg() < 2 {
+		fmt.Println("Usage: > go run PayrollProcessor.go [options] <inputfile> <taxconfigfile> [<taxconfigfile>...]")
+		fmt.Println("Pay periods given without a year e.g. \"01 March – 31 March\" need -year <year> to be accepted")
+		flag.PrintDefaults()
+		return
+	}
+
+	inFile := flag.Arg(0)             // employee details input file
+	taxConfigFiles := flag.Args()[1:] // tax bracket cnfiguration files, one per financial year or other effective date range
+
+	if *outFile == "" {
+		*outFile = PayrollRecord.OutputFileName(inFile)
+	}
+
+	if *rejectsFile == "" {
+		*rejectsFile = PayrollRecord.RejectsFileName(inFile)
+	}
+
+	if *finalise && *ytdFile == "" {
+		fmt.Fprintf(os.Stderr, "Invalid -finalise option: a -ytd store is needed to post the run to\n")
 		return
 	}
 
-	inFile := os.Args[1]        // employee details input file
-	taxConfigFile := os.Args[2] // tax bracket cnfiguration file
+	// apply -round to every figure that wasn't given its own policy
+	if *roundAll != "" {
+		policy, err := Money.ParseRounding(*roundAll)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -round option: %v\n", err)
+			return
+		}
+
+		set := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+		for name, field := range map[string]*Money.Rounding{"round-gross": &PayrollRecord.Rounding.Gross, "round-tax": &PayrollRecord.Rounding.Tax, "round-net": &PayrollRecord.Rounding.Net, "round-super": &PayrollRecord.Rounding.Super} {
+			if !set[name] {
+				*field = policy
+			}
+		}
+	}
+
+	// status and error messages go to standard error, leaving standard output free for streaming output with -o -
 
-	// read tax bracket configs and handle any errors
-	taxBrackets, err := TaxBracket.ReadTaxBracketsConfig(taxConfigFile)
+	// read tax bracket configs (CSV, JSON or YAML, by file extension) and handle any errors - each record is taxed by the table in force for its pay period
+	taxTables, err := TaxBracket.ReadRegistry(taxConfigFiles...)
 	if err != nil {
 		// error reading tax bracket config - abort with error
-		fmt.Printf("Error reading tax brackets config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error reading tax brackets config: %v\n", err)
 		return
 	}
 
+	// read the year-to-date store, if any: the figures carried over from earlier runs
+	if *ytdFile != "" {
+		if PayrollRecord.YTD, err = PayrollRecord.ReadYTDStore(*ytdFile); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return
+		}
+
+		// year-to-date totals are kept by financial year, so every tax table must say which year it is for
+		if err := PayrollRecord.YTD.CheckTables(taxTables); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -ytd option: %v\n", err)
+			return
+		}
+	}
+
 	// read employee payroll information (from CSV  format file)
-	payrollRecords, err := PayrollRecord.ReadPayrollRecords(inFile)
+	payrollRecords, report, err := PayrollRecord.ReadPayrollRecords(inFile)
+	if err != nil {
+		// error reading the input, or mapping its columns - abort rather than write an output (or post a run) missing records
+		fmt.Fprintf(os.Stderr, "Error reading payroll record input: %v\n", err)
+		return
+	}
+
+	// attach any timesheet hours to the records of the hourly employees who worked them
+	if *holidaysFile != "" {
+		if PayrollRecord.PublicHolidays, err = PayrollRecord.ReadHolidays(*holidaysFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading public holidays: %v\n", err)
+			return
+		}
+	}
+
+	if *timesheetFile != "" {
+		timesheet, timesheetReport, err := PayrollRecord.ReadTimesheet(*timesheetFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading timesheet: %v\n", err)
+			return
+		}
+
+		report = append(report, timesheetReport...)
+		report = append(report, PayrollRecord.AttachTimesheet(*timesheetFile, payrollRecords, timesheet)...)
+	}
+
+	// attach any earnings lines to the records they are paid with
+	if *earningsFile != "" {
+		earnings, earningsReport, err := PayrollRecord.ReadEarnings(*earningsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading earnings: %v\n", err)
+			return
+		}
+
+		report = append(report, earningsReport...)
+		report = append(report, PayrollRecord.AttachEarnings(*earningsFile, payrollRecords, earnings)...)
+	}
+
+	// attach any standing deductions to the records of the employees they are for
+	if *deductionsFile != "" {
+		deductions, deductionsReport, err := PayrollRecord.ReadDeductions(*deductionsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading deductions: %v\n", err)
+			return
+		}
+
+		report = append(report, deductionsReport...)
+		report = append(report, PayrollRecord.AttachDeductions(*deductionsFile, payrollRecords, deductions)...)
+	}
+
+	// employees with an invalid timesheet, earnings or deductions row can't be paid correctly without it
+	report = append(report, PayrollRecord.RejectEmployees(inFile, payrollRecords, report)...)
+
+	// records with no tax table in force for their pay period, or a tax scale their table doesn't define, can't be taxed
+	report = append(report, PayrollRecord.CheckTaxTables(inFile, payrollRecords, taxTables)...)
+
+	// report any invalid input records: they are left out of the output and written to a rejects file for correction
+	for _, verr := range report {
+		fmt.Fprintf(os.Stderr, "Invalid payroll record: %v\n", verr)
+	}
+
+	// the rejects are written first: if they can't be, nothing else is written either, so no run leaves output without its rejects
+	rejected, err := PayrollRecord.WriteRejectsFile(*rejectsFile, payrollRecords)
 	if err != nil {
-		fmt.Printf("Error reading payroll record input: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error writing rejected payroll records: %v\n", err)
+		return
 	}
 
-	// once data is read in, pass them into along with input filename and tax bracket information to write output file (CSV)
-	err = PayrollRecord.WriteOutputFile(inFile, payrollRecords, taxBrackets)
+	if rejected > 0 {
+		fmt.Fprintf(os.Stderr, "%d rejected payroll records written to %s\n", rejected, *rejectsFile)
+	}
+
+	// track super against the concessional contributions cap across each employee's records, in input order
+	for _, warning := range PayrollRecord.TrackConcessionalCap(payrollRecords, taxTables) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", warning)
+	}
+
+	// work out each employee's year-to-date totals, carried on from the YTD store
+	if err := PayrollRecord.AccumulateYTD(payrollRecords, taxTables); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return
+	}
+
+	// once data is read in, pass them into along with output filename and tax bracket information to write output file
+	err = PayrollRecord.WriteOutputFile(*outFile, payrollRecords, taxTables)
 	if err != nil {
-		fmt.Printf("Error writing payroll record output: %v", err)
+		fmt.Fprintf(os.Stderr, "Error writing payroll record output: %v\n", err)
+		return
+	}
+
+	// a finalised run's totals are posted to the YTD store, which is only replaced once fully written
+	if *finalise {
+		if err := PayrollRecord.YTD.Post(payrollRecords, taxTables); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return
+		}
+
+		if err := PayrollRecord.YTD.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving year-to-date totals: %v\n", err)
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "Year-to-date totals posted to %s\n", *ytdFile)
 	}
 }
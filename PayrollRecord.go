@@ -1,44 +1,48 @@
 package PayrollRecord
 
 import (
+	"Money"
 	"TaxBracket"
 	"encoding/csv"
 	"fmt"
 	"io"
-	"math"
 	"os"
-	"regexp"
-	"strconv"
+	"path/filepath"
 	"strings"
 )
 
-// round() round values to whole dollar (if  >= .50 round up, else round down)
-func round(n float64) float64 {
-	n_floor := math.Floor(n) // extract whole value
-	n_dec := n - n_floor     // extract decimal portion
-
-	// round up if >= .50
-	if n_dec >= .50 {
-		// round up
-		return n_floor + 1.0
-	}
-
-	// else round down
-	return n_floor
+// RoundingPolicies selects the rounding applied to each calculated figure of a payroll record
+type RoundingPolicies struct {
+	Gross Money.Rounding // rounding of gross income
+	Tax   Money.Rounding // rounding of income tax
+	Net   Money.Rounding // rounding of net income
+	Super Money.Rounding // rounding of super amount
 }
 
-// readPayrollRecords reads in a set of employe payroll info from a specified file and returns that data along with any error encountered
-func ReadPayrollRecords(inputFile string) ([]*PayrollRecord, error) {
+// Rounding holds the rounding policies used by the PayrollRecord calculation methods. The zero value rounds every figure half-up to whole dollars.
+var Rounding RoundingPolicies
+
+// readPayrollRecords reads in a set of employe payroll info from a specified file and returns the records read, a report of the
+// problems found with invalid rows, and any error encountered reading the file. Invalid rows are returned as records with Valid set to false.
+// If the first row is a header naming the input fields (see Columns), columns are picked out by header name, otherwise by position.
+func ReadPayrollRecords(inputFile string) ([]*PayrollRecord, ValidationReport, error) {
 	// open input file
 	fileHandle, err := os.Open(inputFile)
 	if err != nil {
-		return nil, err // if an error encountered opening file, return error to caller with nil data (go methods can return multiple values)
+		return nil, nil, err // if an error encountered opening file, return error to caller with nil data (go methods can return multiple values)
 	}
 	defer fileHandle.Close() // defer file closure so file will auto-close at function return
 
 	// prepare new CSV reader and slice of PayrollRecord objects to read in data
 	csvReader := csv.NewReader(fileHandle)
+	csvReader.Comma = Delimiter
+	csvReader.FieldsPerRecord = -1 // rows may carry optional and extra columns
 	records := []*PayrollRecord{}
+	report := ValidationReport{}
+
+	var index map[string]int // column index of each input field, if the file has a header row
+	var headerRow []string   // the header row itself, kept for rejects
+	first := true
 
 	// per row in input CSV file
 	for {
@@ -48,32 +52,87 @@ func ReadPayrollRecords(inputFile string) ([]*PayrollRecord, error) {
 				err = nil // if EOF, set error to nil: in that case we'll return nil error and the read set of records
 			}
 
-			// return set of records read, along with any error encountered
-			return records, err
+			// each employee ID must identify just one employee, paid no more than once for any day
+			if err == nil {
+				report = append(report, checkEmployeeIDs(inputFile, records)...)
+			}
+
+			// return set of records read and validation report, along with any error encountered
+			return records, report, err
+		}
+
+		line, _ := csvReader.FieldPos(0) // line number of this row, for reporting
+
+		// check first row for a header: a column mapping can only be applied to a file with one
+		if first {
+			first = false
+
+			var header bool
+			if index, header = headerIndex(row, Columns); header {
+				headerRow = row
+				continue
+			}
+
+			if len(Columns) > 0 {
+				return nil, nil, fmt.Errorf("Column mapping given but no header row naming the input fields found in <%s>", inputFile)
+			}
+		}
+
+		inputRow := row // row as given in the input file, kept for rejects
+		if index != nil {
+			row = mapRow(row, index)
 		}
 
 		// send read-in row to create new payroll input record object
 		newPayrollRecord, err := createPayrollRecord(row)
 		if err != nil {
-			// output error and move onto next record
-			fmt.Printf("Error creating payroll input record object %v\n", err)
-			continue
+			// record where the validation errors were found
+			rowReport, ok := err.(ValidationReport)
+			if !ok {
+				rowReport = ValidationReport{&ValidationError{Value: strings.Join(row, ","), Reason: err.Error()}}
+			}
+			rowReport.locate(inputFile, line)
+			report = append(report, rowReport...)
+
+			// rows too short to create a record from are still returned as invalid records
+			if newPayrollRecord == nil {
+				newPayrollRecord = &PayrollRecord{Valid: false, ErrorStr: fmt.Sprintf("Invalid input record: %v", row), Errors: rowReport}
+			}
 		}
 
+		newPayrollRecord.Line = line
+		newPayrollRecord.Row = inputRow
+		newPayrollRecord.Header = headerRow
 		records = append(records, newPayrollRecord)
 	}
 }
 
 // struct representing a payroll record from  the input file: a struct is a composite data type which can have associated methods
 type PayrollRecord struct {
+	EmployeeID   string // identifies the employee, who may have several records in a run (optional: without it employees are told apart by name)
 	FirstName    string
 	LastName     string
-	AnnualSalary float64
-	SuperRate    float64
-	PaymentDate  string
-	Valid        bool   //	indicates if the record object is valid
-	ErrorStr     string // if Valid == false, contains the input data from the input file leading to invalid object
+	AnnualSalary Money.Amount // zero for hourly employees
+	HourlyRate   Money.Amount // usual hourly rate of an employee paid from timesheets, zero for salaried employees
+	SuperRate    Money.Rate
+	Period       PayPeriod        // dates covered by this payment
+	Frequency    PayFrequency     // how often this employee is paid
+	Scale        string           // name of the tax scale this employee is taxed on, empty for the default scale (see TaxBracket.Scale)
+	StudyLoan    bool             // whether this employee has a study loan (HELP debt) to repay
+	Valid        bool             //	indicates if the record object is valid
+	ErrorStr     string           // if Valid == false, contains the input data from the input file leading to invalid object
+	Errors       ValidationReport // if Valid == false, the problems found with the input fields
+	Line         int              // line number of the record in the input file
+	Row          []string         // the record's row as given in the input file
+	Header       []string         // the input file's header row, if it has one
+	Deductions   []*Deduction     // standing deductions from the employee's pay (see AttachDeductions)
+	Earnings     []*Earnings      // earnings on top of salary paid this pay period (see AttachEarnings)
+	Timesheet    []*TimesheetLine // hours worked this pay period by an hourly employee (see AttachTimesheet)
 	TaxBrackets  []*TaxBracket.IncomeTaxBracket
+
+	// figures carried between the records of a run
+	ConcessionalYTD Money.Amount // concessional super contributions for the employee earlier in the financial year (see TrackConcessionalCap)
+	YTD             *YTDTotals   // the employee's year-to-date totals including this record, if a YTD store is in use (see AccumulateYTD)
 }
 
 // -------- methods associated with the PayrollRecord struct -----------
@@ -83,189 +142,551 @@ func (rec *PayrollRecord) FullName() string {
 	return rec.FirstName + " " + rec.LastName
 }
 
-// get pay period for this payroll record
+// employeeKey identifies the employee a record belongs to, for tracking figures across the records of a run and between runs:
+// their employee ID if they have one, otherwise their name
+func (rec *PayrollRecord) employeeKey() string {
+	if rec.EmployeeID != "" {
+		return normaliseKey(rec.EmployeeID)
+	}
+
+	return normaliseKey(rec.FullName())
+}
+
+// isEmployee reports whether an employee as named in a deductions, earnings or timesheet file (by employee ID or full name)
+// is this record's employee
+func (rec *PayrollRecord) isEmployee(employee string) bool {
+	key := normaliseKey(employee)
+	return key == rec.employeeKey() || key == normaliseKey(rec.FullName())
+}
+
+// sharedNames returns the names (as normalised by normaliseKey) of the employees with valid records who share their name with
+// another employee: they can only be told apart by employee ID
+func sharedNames(records []*PayrollRecord) map[string]bool {
+	keys := map[string]string{} // employee key of the first record found with each name
+	shared := map[string]bool{}
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		name := normaliseKey(rec.FullName())
+		if key, ok := keys[name]; ok && key != rec.employeeKey() {
+			shared[name] = true
+		}
+		keys[name] = rec.employeeKey()
+	}
+
+	return shared
+}
+
+// hasEmployeeIDs reports whether any valid record has an employee ID
+func hasEmployeeIDs(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if rec.Valid && rec.EmployeeID != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get pay period for this payroll record, as given in the input file
 func (rec *PayrollRecord) PayPeriod() string {
-	return rec.PaymentDate
-}
-
-// get gross income for this payroll record
-func (rec *PayrollRecord) GrossIncome() float64 {
-	return round(rec.AnnualSalary / 12)
-}
-
-// calculate monthly income tax for this payroll record (takes the income tax bracket data provided by the TaxBracket package)
-func (rec *PayrollRecord) IncomeTax(taxBrackets []*TaxBracket.IncomeTaxBracket) (float64, error) {
-	// iterate through tax brackets and find the right tax percentage, limit above which percentage tax is payable and any lump sum payable for this salary amount
-	perc := 0.0
-	lump := 0.0
-	abv := 0.0
-	set := false // flag denoting that a fitting tax bracket was found
-
-	// for each tax bracket configured
-	for _, brac := range taxBrackets {
-		if brac.Upper == 0 {
-			// upper limit would be automatically set to zero for topmost tax bracket - check if salary is bigger than top bracket lower
-			if rec.AnnualSalary >= brac.Lower {
-				perc = brac.Percent
-				lump = brac.Lump
-				abv = brac.Above
-				set = true
-			}
+	return rec.Period.String()
+}
 
-		} else {
-			// if not the topmost tax bracket, check if the salary falls between the lower and upper limist of this bracket
-			if rec.AnnualSalary >= brac.Lower && rec.AnnualSalary <= brac.Upper {
-				perc = brac.Percent
-				lump = brac.Lump
-				abv = brac.Above
-				set = true
-			}
+// perPeriod converts an annual figure to the amount for this record's pay period: divided by the number of pay periods in a year
+// and pro-rated for a partial period
+func (rec *PayrollRecord) perPeriod(annual Money.Exact) Money.Exact {
+	num, den := rec.ProrationFactor()
+	return annual.Div(rec.Frequency.PeriodsPerYear()).MulFrac(num, den)
+}
+
+// get gross income for this payroll record: annual salary divided across the pay periods in a year, pro-rated for a partial period,
+// plus any earnings lines and timesheet hours paid this pay period
+func (rec *PayrollRecord) GrossIncome() Money.Amount {
+	timesheet, _ := rec.timesheetPay()
+	return rec.salaryIncome() + rec.earningsTotal(func(*Earnings) bool { return true }) + timesheet
+}
+
+// salaryIncome calculates this pay period's share of the annual salary, pro-rated for a partial period
+func (rec *PayrollRecord) salaryIncome() Money.Amount {
+	return rec.perPeriod(rec.AnnualSalary.Exact()).Round(Rounding.Gross)
+}
+
+// find the tax table in force for this payroll record: the one in force on the last day of its pay period
+func (rec *PayrollRecord) TaxTable(registry *TaxBracket.Registry) (*TaxBracket.TaxTable, error) {
+	return registry.TableFor(rec.Period.End)
+}
+
+// find the tax bracket this payroll record's taxable income (see TaxableIncome) falls in on its tax scale
+func (rec *PayrollRecord) TaxBracket(table *TaxBracket.TaxTable) (*TaxBracket.IncomeTaxBracket, error) {
+	scale, err := table.Scale(rec.Scale)
+	if err != nil {
+		return nil, err
+	}
+
+	return TaxBracket.FindBracket(scale.Brackets, rec.TaxableIncome())
+}
+
+// calculate income tax per pay period for this payroll record (takes the tax table provided by the TaxBracket package):
+// bracket tax less any tax offsets, plus the tax on any marginal earnings lines
+func (rec *PayrollRecord) IncomeTax(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	tax, err := rec.bracketTax(table)
+	if err != nil {
+		return -1, err
+	}
+
+	offsets, err := rec.Offsets(table)
+	if err != nil {
+		return -1, err
+	}
+
+	for _, offset := range offsets {
+		tax -= offset.Amount
+	}
+
+	marginalTax, err := rec.marginal(func(income Money.Amount) (Money.Exact, error) { return rec.annualIncomeTax(table, income) })
+	if err != nil {
+		return -1, err
+	}
+
+	return tax + marginalTax, nil
+}
+
+// annualIncomeTax calculates the income tax on an annual income on this record's tax scale: bracket tax less the offsets
+// applying to the scale, never below zero
+func (rec *PayrollRecord) annualIncomeTax(table *TaxBracket.TaxTable, income Money.Amount) (Money.Exact, error) {
+	scale, err := table.Scale(rec.Scale)
+	if err != nil {
+		return Money.Exact{}, err
+	}
+
+	brac, err := TaxBracket.FindBracket(scale.Brackets, income)
+	if err != nil {
+		return Money.Exact{}, err
+	}
+
+	tax := brac.AnnualTax(income)
+	for _, offset := range table.Offsets {
+		if scale.Applies(offset.Name) {
+			tax = tax.Sub(offset.AnnualOffset(income))
 		}
 	}
 
-	if !set {
-		// no fitting bracket found for this salary - return error
-		return -1.0, fmt.Errorf("No fitting tax bracket was found for salary amount %f", rec.AnnualSalary)
+	if zero := Money.Amount(0).Exact(); tax.Cmp(zero) < 0 {
+		return zero, nil
 	}
 
-	// percentage annual tax payable is the set percentage of percentage taxable portion
-	percentageTax := ((rec.AnnualSalary - abv) * perc) / 100
+	return tax, nil
+}
+
+// bracketTax calculates the tax per pay period for this payroll record from its tax bracket alone, before offsets
+func (rec *PayrollRecord) bracketTax(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	// find the tax bracket giving the tax percentage, limit above which percentage tax is payable and any lump sum payable for this salary amount
+	brac, err := rec.TaxBracket(table)
+	if err != nil {
+		return -1, err
+	}
+
+	// divide annual tax by the number of pay periods in a year to get tax payable this period, pro-rated for a partial period - round to given specification
+	return rec.perPeriod(brac.AnnualTax(rec.TaxableIncome())).Round(Rounding.Tax), nil
+}
 
-	// add any applicable lump payment to annual percentage tax and divide by 12 to get monthly payable tax - use round() to round to given specification
-	return round((percentageTax + lump) / 12), nil
+// OffsetAmount is the amount one tax offset reduces income tax by for a pay period
+type OffsetAmount struct {
+	Name   string       `json:"name"`
+	Amount Money.Amount `json:"amount"`
 }
 
-// calculate net income value for this salary (and return any error)
-func (rec *PayrollRecord) NetIncome(taxBrackets []*TaxBracket.IncomeTaxBracket) (float64, error) {
-	gross := rec.GrossIncome()             // gross monthly income
-	tax, err := rec.IncomeTax(taxBrackets) // monthly tax payable
+// calculate each tax offset in the tax table per pay period for this payroll record, rounded as income tax is. Offsets are applied
+// in order, each limited to the bracket tax the ones before it left, so together they never take income tax below zero.
+// Offsets the record's tax scale is exempt from are left out.
+func (rec *PayrollRecord) Offsets(table *TaxBracket.TaxTable) ([]OffsetAmount, error) {
+	remaining, err := rec.bracketTax(table)
+	if err != nil {
+		return nil, err
+	}
 
+	scale, err := table.Scale(rec.Scale)
 	if err != nil {
-		return -1.0, err // return error if any encountered calculating income tax
+		return nil, err
+	}
+
+	offsets := []OffsetAmount{}
+	for _, offset := range table.Offsets {
+		if !scale.Applies(offset.Name) {
+			continue
+		}
+
+		amount := rec.perPeriod(offset.AnnualOffset(rec.TaxableIncome())).Round(Rounding.Tax)
+		if amount > remaining {
+			amount = remaining
+		}
+
+		remaining -= amount
+		offsets = append(offsets, OffsetAmount{offset.Name, amount})
 	}
 
+	return offsets, nil
+}
+
+// LevyAmount is the amount of one levy payable for a pay period
+type LevyAmount struct {
+	Name   string       `json:"name"`
+	Amount Money.Amount `json:"amount"`
+}
+
+// calculate each levy in the tax table payable per pay period for this payroll record, rounded as income tax is, including
+// the levy on any marginal earnings lines. Levies the record's tax scale is exempt from are left out.
+func (rec *PayrollRecord) Levies(table *TaxBracket.TaxTable) []LevyAmount {
+	levies := []LevyAmount{}
+	scale, err := table.Scale(rec.Scale)
+	if err != nil {
+		return levies // no scale, no tax: IncomeTax reports the error
+	}
+
+	for _, levy := range table.Levies {
+		if !scale.Applies(levy.Name) {
+			continue
+		}
+
+		amount := rec.perPeriod(levy.AnnualLevy(rec.TaxableIncome())).Round(Rounding.Tax)
+		marginalLevy, _ := rec.marginal(func(income Money.Amount) (Money.Exact, error) { return levy.AnnualLevy(income), nil })
+		levies = append(levies, LevyAmount{levy.Name, amount + marginalLevy})
+	}
+
+	return levies
+}
+
+// calculate study loan repayment per pay period for this payroll record, rounded as income tax is: a percentage of the whole
+// taxable income from the tax table's repayment bands, plus the repayment on any marginal earnings lines, or zero if the employee
+// has no study loan
+func (rec *PayrollRecord) StudyLoanRepayment(table *TaxBracket.TaxTable) Money.Amount {
+	if !rec.StudyLoan {
+		return 0
+	}
+
+	marginalRepayment, _ := rec.marginal(func(income Money.Amount) (Money.Exact, error) { return table.AnnualRepayment(income), nil })
+	return rec.perPeriod(table.AnnualRepayment(rec.TaxableIncome())).Round(Rounding.Tax) + marginalRepayment
+}
+
+// calculate net income value for this salary: net pay (see netPay) less post-tax deductions (and return any error)
+func (rec *PayrollRecord) NetIncome(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	net, err := rec.netPay(table)
+	if err != nil {
+		return -1, err
+	}
+
+	deductions, err := rec.PostTaxDeductions(table)
+	if err != nil {
+		return -1, err
+	}
+
+	return net - totalOf(deductions), nil
+}
+
+// netPay calculates the pay left after tax: gross income less pre-tax deductions, income tax, levies and any study loan repayment
+func (rec *PayrollRecord) netPay(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	gross := rec.GrossIncome() - totalOf(rec.PreTaxDeductions()) // gross income this pay period, less pre-tax deductions
+	tax, err := rec.IncomeTax(table)                             // tax payable this pay period
+
+	if err != nil {
+		return -1, err // return error if any encountered calculating income tax
+	}
+
+	// levies and study loan repayments are withheld along with income tax
+	for _, levy := range rec.Levies(table) {
+		tax += levy.Amount
+	}
+	tax += rec.StudyLoanRepayment(table)
+
 	// sanity check to ensure tax payable isn't larger than gross income
 	if tax > gross {
-		return -1.0, fmt.Errorf("Taxed amount (%f) larger than gross income (%f)", tax, gross)
+		return -1, fmt.Errorf("Taxed amount (%s) larger than gross income after pre-tax deductions (%s)", tax, gross)
+	}
+
+	// return net pay value
+	return (gross - tax).Exact().Round(Rounding.Net), nil
+}
+
+// calculate superannuation for this payroll record (see uncappedSuper). When SuperCap is LimitToCap the employer's contribution is
+// limited by the tax table's concessional contributions cap: to no more than the cap less the employee's concessional contributions so
+// far this year (ConcessionalYTD) and their salary sacrifice. Salary sacrificed is always paid in full, having been deducted from pay.
+func (rec *PayrollRecord) SuperAmount(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	super, err := rec.employerSuper(table)
+	if err != nil {
+		return -1, err
+	}
+
+	sacrifice := rec.SalarySacrifice()
+	if SuperCap == LimitToCap && table.Super.ConcessionalCap > 0 {
+		room := table.Super.ConcessionalCap - rec.ConcessionalYTD - sacrifice
+		if room < 0 {
+			room = 0
+		}
+
+		if super > room {
+			super = room
+		}
+	}
+
+	return super + sacrifice, nil
+}
+
+// uncappedSuper calculates superannuation for this payroll record before any concessional cap: the employer's contribution
+// (see employerSuper) plus any salary sacrificed into super
+func (rec *PayrollRecord) uncappedSuper(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	super, err := rec.employerSuper(table)
+	if err != nil {
+		return -1, err
 	}
 
-	// return net income value
-	return round(gross - tax), nil
+	return super + rec.SalarySacrifice(), nil
 }
 
-// calculate superannuation
-func (rec *PayrollRecord) SuperAmount() (float64, error) {
-	if rec.SuperRate < 0 || rec.SuperRate > 50 {
-		return -1.0, fmt.Errorf("Invalid super rate (%f)", rec.SuperRate)
+// employerSuper calculates the super rate's share of ordinary time earnings (salary, timesheet pay and super-eligible earnings lines),
+// counting no more of them than the tax table's maximum contribution base allows for the pay period (a quarterly limit, spread evenly
+// across the year's pay periods)
+func (rec *PayrollRecord) employerSuper(table *TaxBracket.TaxTable) (Money.Amount, error) {
+	if rec.SuperRate < 0 || rec.SuperRate > 50*Money.RateScale {
+		return -1, fmt.Errorf("Invalid super rate (%s)", rec.SuperRate)
+	}
+
+	timesheet, _ := rec.timesheetPay()
+	earnings := (rec.salaryIncome() + rec.earningsTotal(func(e *Earnings) bool { return e.Super }) + timesheet).Exact()
+	if base := table.Super.MaxContributionBase; base > 0 {
+		if limit := rec.perPeriod(base.Exact().MulFrac(4, 1)); limit.Cmp(earnings) < 0 {
+			earnings = limit
+		}
 	}
 
-	super := (rec.GrossIncome() * rec.SuperRate) / 100
-	return round(super), nil
+	return earnings.MulRate(rec.SuperRate).Round(Rounding.Super), nil
 }
 
 // print payroll input record, mostly for debug puposes
 func (rec *PayrollRecord) Print() {
 	if rec.Valid {
-		fmt.Printf("%s, %s, %.2f, %.2f%%, %s, %f\n", rec.FirstName, rec.LastName, rec.AnnualSalary, rec.SuperRate, rec.PaymentDate, rec.GrossIncome())
+		fmt.Printf("%s, %s, %s, %s, %s, %s\n", rec.FirstName, rec.LastName, rec.AnnualSalary, rec.SuperRate, rec.Period, rec.GrossIncome())
 	} else {
 		fmt.Printf("Invalid record %s\n", rec.ErrorStr)
 	}
 }
 
-// createPayrollRecord takes a string slice (input row from input records file), creates a payroll record struct instance, and returns a reference to it
+// createPayrollRecord takes a string slice (input row from input records file), creates a payroll record struct instance, and returns a reference to it.
+// Any invalid fields are returned as a ValidationReport error, with the record marked invalid and listing them in its Errors.
 func createPayrollRecord(inputRow []string) (*PayrollRecord, error) {
-	// ensure input CSV row has minimum required number of fields (first, last, annual, super, startdate)
+	// ensure input CSV row has minimum required number of fields (first, last, annual, super, startdate) - an optional sixth field gives the pay frequency
+	// an optional seventh the tax scale, an optional eighth whether the employee has a study loan, an optional ninth the hourly rate
+	// of an employee paid from timesheets rather than a salary and an optional tenth the employee ID
 	if len(inputRow) < 5 {
-		return nil, fmt.Errorf("Input row must have atleast five fields %v", inputRow)
+		report := ValidationReport{}
+		report.add("", strings.Join(inputRow, ","), "input row must have at least five fields")
+		return nil, report
 	}
 
 	// declare new empty record struct instance
 	var newRecord PayrollRecord
+	report := ValidationReport{} // problems found with the input fields
 
 	// prepare input
 	FirstName := strings.TrimSpace(inputRow[0])
 	LastName := strings.TrimSpace(inputRow[1])
-	AnnualSalary, err_sal := strconv.ParseFloat(inputRow[2], 64)
+	AnnualSalary, err_sal := Money.Parse(inputRow[2])
 	SuperRate := strings.TrimSpace(inputRow[3])
 	PaymentDate := strings.TrimSpace(inputRow[4])
 
-	// extract numeric super percentage value e.g. 50 from "50%"
-	rexp, _ := regexp.Compile("^[0-9]+")                                      // use regular expression to match numeric portion
-	SuperRate_f, err_sr := strconv.ParseFloat(rexp.FindString(SuperRate), 64) // convert it to numeric value
+	// extract numeric super percentage value e.g. 9.5 from "9.5%"
+	SuperRate_r, err_sr := Money.ParseRate(SuperRate)
+
+	// parse pay period into start and end dates
+	Period, err_pp := ParsePayPeriod(PaymentDate, PeriodYear)
 
 	// sanity check values
+	if FirstName == "" {
+		report.add("first", inputRow[0], "first name is required")
+	}
+	if LastName == "" {
+		report.add("last", inputRow[1], "last name is required")
+	}
+	// hourly employees give an hourly rate in the optional ninth field instead of an annual salary
+	var HourlyRate Money.Amount
+	if len(inputRow) > 8 && strings.TrimSpace(inputRow[8]) != "" {
+		var err_hr error
+		if HourlyRate, err_hr = Money.Parse(inputRow[8]); err_hr != nil || HourlyRate <= 0 {
+			report.add("rate", inputRow[8], "hourly rate must be a money value greater than zero")
+		}
+		if strings.TrimSpace(inputRow[2]) != "" {
+			report.add("salary", inputRow[2], "give either an annual salary or an hourly rate, not both")
+		}
+	} else if err_sal != nil {
+		report.add("salary", inputRow[2], "not a valid money value")
+	} else if AnnualSalary <= 0 {
+		report.add("salary", inputRow[2], "annual salary must be greater than zero")
+	}
+	if err_sr != nil {
+		report.add("super", inputRow[3], "not a valid percentage")
+	} else if SuperRate_r < 0 || SuperRate_r > 50*Money.RateScale {
+		report.add("super", inputRow[3], "super rate must be between 0% and 50%")
+	}
+	if err_pp != nil {
+		report.add("period", inputRow[4], err_pp.Error())
+	}
+
+	// pay frequency is taken from the optional sixth field if given, otherwise worked out from the pay period dates
+	var Frequency PayFrequency
+	if len(inputRow) > 5 && strings.TrimSpace(inputRow[5]) != "" {
+		var err_fr error
+		if Frequency, err_fr = ParsePayFrequency(inputRow[5]); err_fr != nil {
+			report.add("frequency", inputRow[5], "unknown pay frequency (weekly, fortnightly, semi-monthly, monthly or quarterly)")
+		}
+	} else if err_pp == nil {
+		var err_fr error
+		if Frequency, err_fr = DeriveFrequency(Period); err_fr != nil {
+			report.add("period", inputRow[4], "unable to work out pay frequency from pay period; give it in the frequency field")
+		}
+	}
+
+	// tax scale is taken from the optional seventh field, if given - scale names are defined by the tax tables, see CheckTaxTables
+	Scale := ""
+	if len(inputRow) > 6 {
+		Scale = strings.ToLower(strings.TrimSpace(inputRow[6]))
+	}
+
+	// study loan flag is taken from the optional eighth field: yes or no, no if not given
+	StudyLoan := false
+	if len(inputRow) > 7 {
+		var err_sl error
+		if StudyLoan, err_sl = parseYesNo(inputRow[7]); err_sl != nil {
+			report.add("help", inputRow[7], "must be yes or no")
+		}
+	}
+
+	// employee ID is taken from the optional tenth field, if given (see checkEmployeeIDs)
+	EmployeeID := ""
+	if len(inputRow) > 9 {
+		EmployeeID = strings.TrimSpace(inputRow[9])
+	}
+
+	// the pay period must fit within a single full period at its frequency (it may be shorter, for starters and leavers)
+	if len(report) == 0 && Period.End.After(Frequency.FullPeriod(Period).End) {
+		report.add("period", inputRow[4], fmt.Sprintf("pay period is longer than a %s pay period", Frequency))
+	}
+
 	// if an error is encountered and the program is unable to create a valid record, a struct instance with
 	// Valid attribute set to false will be returned with an error string rather than aborting.
-	if FirstName == "" || LastName == "" || (err_sal != nil) || (err_sr != nil) || AnnualSalary <= 0 || SuperRate_f < 0 || SuperRate_f > 50 {
+	if len(report) > 0 {
 		newRecord.Valid = false
 		newRecord.ErrorStr = fmt.Sprintf("Invalid input record: [%s] [%s] [%s] [%s] [%s]", inputRow[0], inputRow[1], inputRow[2], inputRow[3], inputRow[4])
-		return &newRecord, fmt.Errorf("Invalid data in payroll input record\n")
+		newRecord.Errors = report
+		return &newRecord, report
 	}
 
 	// assign values to struct instance's fields
+	newRecord.EmployeeID = EmployeeID
 	newRecord.FirstName = FirstName
 	newRecord.LastName = LastName
 	newRecord.AnnualSalary = AnnualSalary
-	newRecord.SuperRate = SuperRate_f
-	newRecord.PaymentDate = PaymentDate
+	newRecord.HourlyRate = HourlyRate
+	newRecord.SuperRate = SuperRate_r
+	newRecord.Period = Period
+	newRecord.Frequency = Frequency
+	newRecord.Scale = Scale
+	newRecord.StudyLoan = StudyLoan
 	newRecord.Valid = true
 
 	// return reference to struct and nil error
 	return &newRecord, nil
 }
 
-// writeOutputFile() takes the input filename, slice of read-in payroll structs and tax bracket config and writes the required output file (CSV)
-func WriteOutputFile(inFileName string, records []*PayrollRecord, taxBrackets []*TaxBracket.IncomeTaxBracket) error {
-	// sanity check input filename
-	if strings.TrimSpace(inFileName) == "" {
-		return fmt.Errorf("Invalid input filename")
+// parseYesNo reads a yes/no input field: yes, y, true or 1, or no, n, false, 0 or empty
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "true", "1":
+		return true, nil
+	case "no", "n", "false", "0", "":
+		return false, nil
 	}
 
-	// create output filename e.g. input.csv -> input-out.csv
-	outFileName := strings.Split(strings.TrimSpace(inFileName), ".")[0] + "-out.csv"
-	f, err := os.Create(outFileName)
+	return false, fmt.Errorf("Invalid yes/no value <%s>", s)
+}
 
-	if err != nil {
-		return fmt.Errorf("Error creating outputfile <%s>: %v", outFileName, err) // return error if encountered creating file
+// OutputFileName returns the default output filename for an input file in the OutputFormat e.g. ./data/v1.2/input.csv -> ./data/v1.2/input-out.csv
+func OutputFileName(inFileName string) string {
+	return outputBaseName(inFileName) + "-out." + OutputFormat.String()
+}
+
+// outputBaseName returns the input filename with its extension removed, to name output files after e.g. input.csv -> input
+func outputBaseName(inFileName string) string {
+	inFileName = strings.TrimSpace(inFileName)
+	return strings.TrimSuffix(inFileName, filepath.Ext(inFileName))
+}
+
+// writeOutputFile() takes the output filename, slice of read-in payroll structs and tax table registry and writes the required output file in the OutputFormat.
+// An output filename of "-" writes to standard output. See writeFile for how existing files are treated.
+func WriteOutputFile(outFileName string, records []*PayrollRecord, registry *TaxBracket.Registry) error {
+	// sanity check output filename
+	if strings.TrimSpace(outFileName) == "" {
+		return fmt.Errorf("Invalid output filename")
+	}
+
+	return writeFile(outFileName, func(w io.Writer) error {
+		return WriteOutput(w, records, registry)
+	})
+}
+
+// WriteOutput writes the processed payroll records in the OutputFormat
+func WriteOutput(w io.Writer, records []*PayrollRecord, registry *TaxBracket.Registry) error {
+	switch OutputFormat {
+	case JSON:
+		return writeJSON(w, records, registry, false)
+	case JSONLines:
+		return writeJSON(w, records, registry, true)
 	}
 
-	defer f.Close() // defer file closure to function exit
+	return writeCSV(w, records, registry)
+}
+
+// writeCSV writes the processed payroll records as CSV: a header row (see OutputHeader) followed by a row of the fields in OutputFields
+// for each valid record, with a column for each levy and each tax offset defined in the tax tables, one for study loan repayments
+// two for pre-tax deductions (their total and the part of it sacrificed into super), one for post-tax deductions, one for
+// earnings lines, two for timesheets (hours worked and the pay for them) and eight for year-to-date totals
+func writeCSV(w io.Writer, records []*PayrollRecord, registry *TaxBracket.Registry) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = Delimiter
+
+	// each levy and offset has its own column after the OutputFields, followed by study loan repayments if any table has them
+	// and pre-tax and post-tax deductions, earnings lines, timesheet hours and year-to-date totals if any employee has them
+	items := lineItemNames{registry.LevyNames(), registry.OffsetNames(), registry.HasStudyLoan(), hasPreTaxDeductions(records), hasPostTaxDeductions(records),
+		hasEarnings(records), hasTimesheets(records), hasYTD(records), hasEmployeeIDs(records)}
+
+	if OutputHeader {
+		if err := csvWriter.Write(outputHeader(items)); err != nil {
+			return fmt.Errorf("Error writing CSV output: %v", err)
+		}
+	}
 
 	// for each payroll input record
 	for _, rec := range records {
-		if rec.Valid { // if record is valid
+		if rec.Valid { // if record is valid - invalid records are left out of the output, see WriteRejectsFile
 			// get record data
-			name := rec.FullName()
-			payp := rec.PayPeriod()
-			gross := rec.GrossIncome()
-			tax, err := rec.IncomeTax(taxBrackets)
+			payslip, err := rec.Payslip(registry)
 			if err != nil {
-				return fmt.Errorf("Error getting income tax: %v", err)
-			}
-
-			net, err := rec.NetIncome(taxBrackets)
-			if err != nil {
-				return fmt.Errorf("Error getting net income: %v", err)
-			}
-
-			super, err := rec.SuperAmount()
-			if err != nil {
-				return fmt.Errorf("Error getting net income: %v", err)
+				return err
 			}
 
 			// write output
-			_, err = f.WriteString(fmt.Sprintf("%s, %s, %.0f,%.0f, %.0f, %.0f\n", name, payp, gross, tax, net, super))
-			if err != nil {
-				return fmt.Errorf("Error writing CSV output: %v", err)
-			}
-		} else {
-			// invalid record
-			_, err := f.WriteString("Invalid payroll record: no output.\n")
-			if err != nil {
+			if err := csvWriter.Write(payslip.row(items)); err != nil {
 				return fmt.Errorf("Error writing CSV output: %v", err)
 			}
 		}
 	}
 
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("Error writing CSV output: %v", err)
+	}
+
 	return nil
 }
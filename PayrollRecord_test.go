@@ -0,0 +1,1559 @@
+// Go tests are placed in files with the pattern *_test.go. Test Methods have the signature func <TestMethod>(t *testing.T) The tests are run with "go test" command.
+
+package PayrollRecord
+
+import (
+	"Money"
+	"TaxBracket"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pay periods in the tests are given without a year, assumed to be 2024
+func TestMain(m *testing.M) {
+	PeriodYear = 2024
+	os.Exit(m.Run())
+}
+
+// tests for ReadPayrollRecords(inputFile string) ([]*PayrollRecord, ValidationReport, error)
+func TestReadPayrollRecords(t *testing.T) {
+	// set of valid input files should produce a nil error
+	files := []string{"test_input_01.csv"}
+
+	for _, fn := range files {
+		_, _, err := ReadPayrollRecords(fn)
+
+		if err != nil {
+			t.Errorf("FAILED: error reading payroll input records file %v", err)
+		}
+	}
+}
+
+// tests for ReadPayrollRecords() with a header row and column mapping
+func TestReadPayrollRecordsColumns(t *testing.T) {
+	dir := t.TempDir()
+
+	// header using the usual names, columns out of order and an extra column
+	plain := filepath.Join(dir, "plain.csv")
+	os.WriteFile(plain, []byte("Employee No,Last Name,First Name,Super Rate,Annual Salary,Pay Period\n"+
+		"E1,Rudd,David,9%,60050,01 March – 31 March\n"), 0644)
+
+	// header with HR export names that need a column mapping
+	mapped := filepath.Join(dir, "mapped.csv")
+	os.WriteFile(mapped, []byte("Surname,Given Name,Base Pay,Cycle,Dates,Super %\n"+
+		"Chen,Ryan,52000,fortnightly,04 March – 17 March,10%\n"), 0644)
+
+	records, _, err := ReadPayrollRecords(plain)
+	if err != nil || len(records) != 1 || records[0].FullName() != "David Rudd" || records[0].AnnualSalary != Money.FromDollars(60050) {
+		t.Errorf("FAILED: ReadPayrollRecords(plain header) = %v, %v", records, err)
+	}
+
+	defer func() { Columns = nil }()
+	Columns, _ = ParseColumnMapping("first=Given Name,salary=Base Pay,super=Super %,period=Dates,frequency=Cycle")
+
+	records, _, err = ReadPayrollRecords(mapped)
+	if err != nil || len(records) != 1 || records[0].FullName() != "Ryan Chen" || records[0].Frequency != Fortnightly {
+		t.Errorf("FAILED: ReadPayrollRecords(mapped header) = %v, %v", records, err)
+	}
+
+	// a mapping can't be applied to a file without a matching header
+	if _, _, err = ReadPayrollRecords(plain); err == nil {
+		t.Errorf("FAILED: ReadPayrollRecords() should fail when the column mapping doesn't match the header")
+	}
+
+	if _, err = ParseColumnMapping("nickname=Nick"); err == nil {
+		t.Errorf("FAILED: ParseColumnMapping() should reject unknown fields")
+	}
+}
+
+// tests for the validation report returned by ReadPayrollRecords()
+func TestValidationReport(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.csv")
+	os.WriteFile(input, []byte("David,Rudd,60050,9%,01 March – 31 March\n"+
+		"Ryan,,abc,9%,01 March – 31 March\n"+
+		"Marcus,Aurelius,850000,75%,01 May – 31 May\n"+
+		"Georgy,Zukhov\n"), 0644)
+
+	records, report, err := ReadPayrollRecords(input)
+	if err != nil || len(records) != 4 || !records[0].Valid || records[1].Valid || records[2].Valid || records[3].Valid {
+		t.Errorf("FAILED: ReadPayrollRecords() = %v, %v: expected one valid and three invalid records", records, err)
+	}
+
+	expected := []ValidationError{
+		{input, 2, "last", "", "last name is required", ""},
+		{input, 2, "salary", "abc", "not a valid money value", ""},
+		{input, 3, "super", "75%", "super rate must be between 0% and 50%", ""},
+		{input, 4, "", "Georgy,Zukhov", "input row must have at least five fields", ""},
+	}
+
+	if len(report) != len(expected) {
+		t.Fatalf("FAILED: ReadPayrollRecords() validation report = %v: expected %d errors", report, len(expected))
+	}
+
+	for i, verr := range report {
+		if *verr != expected[i] {
+			t.Errorf("FAILED: validation error %d = %+v: expected <%+v>", i, *verr, expected[i])
+		}
+	}
+}
+
+// tests for WriteRejectsFile()
+func TestWriteRejectsFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	os.WriteFile(input, []byte("David,Rudd,60050,9%,01 March – 31 March\n"+
+		"Ryan,Chen,abc,9%,01 March – 31 March\n"+
+		"Georgy,Zukhov\n"), 0644)
+
+	records, _, err := ReadPayrollRecords(input)
+	if err != nil {
+		t.Fatalf("FAILED: error reading payroll input records file %v", err)
+	}
+
+	rejects := RejectsFileName(input)
+	if n, err := WriteRejectsFile(rejects, records); err != nil || n != 2 {
+		t.Fatalf("FAILED: WriteRejectsFile() = %d, %v: expected 2 records written", n, err)
+	}
+
+	got, _ := os.ReadFile(rejects)
+	expected := "Line,Reasons,first,last,salary,super,period\n" +
+		"2,salary <abc>: not a valid money value,Ryan,Chen,abc,9%,01 March – 31 March\n" +
+		"3,\"input row must have at least five fields <Georgy,Zukhov>\",Georgy,Zukhov\n"
+	if string(got) != expected {
+		t.Errorf("FAILED: WriteRejectsFile() wrote <%s>: expected <%s>", got, expected)
+	}
+
+	// an input file's own header row heads its rejects, which can be read back in once corrected
+	headed := filepath.Join(dir, "headed.csv")
+	os.WriteFile(headed, []byte("Given Name,Surname,Pay Period,Annual Salary,Super Rate\n"+
+		"David,Rudd,01 March – 31 March,60050,9%\n"+
+		"Ryan,Chen,01 March – 31 March,abc,9%\n"), 0644)
+
+	if records, _, err = ReadPayrollRecords(headed); err != nil {
+		t.Fatalf("FAILED: error reading payroll input records file %v", err)
+	}
+
+	headedRejects := RejectsFileName(headed)
+	if n, err := WriteRejectsFile(headedRejects, records); err != nil || n != 1 {
+		t.Fatalf("FAILED: WriteRejectsFile() = %d, %v: expected 1 record written", n, err)
+	}
+
+	got, _ = os.ReadFile(headedRejects)
+	expected = "Line,Reasons,Given Name,Surname,Pay Period,Annual Salary,Super Rate\n" +
+		"3,salary <abc>: not a valid money value,Ryan,Chen,01 March – 31 March,abc,9%\n"
+	if string(got) != expected {
+		t.Errorf("FAILED: WriteRejectsFile() wrote <%s>: expected <%s>", got, expected)
+	}
+
+	if resubmitted, report, err := ReadPayrollRecords(headedRejects); err != nil || len(resubmitted) != 1 || len(report) != 1 || report[0].Field != "salary" {
+		t.Errorf("FAILED: ReadPayrollRecords() of a rejects file = %d records, %v, %v: expected its one rejected record read back", len(resubmitted), report, err)
+	}
+
+	// a rerun with nothing rejected removes the earlier run's rejects, but only with -force like any other output file
+	if _, err := WriteRejectsFile(rejects, records[:1]); err == nil {
+		t.Errorf("FAILED: WriteRejectsFile() removed an existing rejects file without Overwrite")
+	}
+
+	Overwrite = true
+	defer func() { Overwrite = false }()
+
+	if n, err := WriteRejectsFile(rejects, records[:1]); err != nil || n != 0 {
+		t.Fatalf("FAILED: WriteRejectsFile() = %d, %v: expected no records written", n, err)
+	}
+
+	if _, err := os.Stat(rejects); !os.IsNotExist(err) {
+		t.Errorf("FAILED: WriteRejectsFile() left a stale rejects file with no records rejected")
+	}
+}
+
+// tests for PayrollRecord.FullName()
+func TestFullName(t *testing.T) {
+	// create some test payroll record objects and expected fullnames
+	tests := map[*PayrollRecord]string{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = "David Rudd"
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 March – 31 March", ","))
+	tests[prr] = "Ryan Chen"
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = "Marcus Aurelius"
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,850000,25%,01 May – 31 May", ","))
+	tests[prr] = "Georgy Zukhov"
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,850000,25%,01 May – 31 May", ","))
+	tests[prr] = "John Citizen"
+
+	// test
+	for prr, expected := range tests {
+		got := prr.FullName()
+		if prr.Valid {
+			if got != expected {
+				t.Errorf("FAILED: PayrollRecord.FullName() = %v: expected <%s>", got, expected)
+			}
+		} else {
+			t.Errorf("FAILED: invalid payroll record")
+		}
+	}
+
+}
+
+// tests for PayrollRecord.PayPeriod()
+func TestPayPeriod(t *testing.T) {
+	// create some test payroll record objects and expected pay periods
+	tests := map[*PayrollRecord]string{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = "01 March – 31 March"
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 Apr – 30 Apr", ","))
+	tests[prr] = "01 Apr – 30 Apr"
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = "01 May – 31 May"
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,850000,25%,01 Dec – 31 Dec", ","))
+	tests[prr] = "01 Dec – 31 Dec"
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,850000,25%,01 Jun – 30 Jun", ","))
+	tests[prr] = "01 Jun – 30 Jun"
+
+	// test
+	for prr, expected := range tests {
+		got := prr.PayPeriod()
+		if prr.Valid {
+			if got != expected {
+				t.Errorf("FAILED: PayrollRecord.PayPeriod() = %v: expected <%s>", got, expected)
+			}
+		} else {
+			t.Errorf("FAILED: invalid payroll record")
+		}
+	}
+}
+
+// tests for ParsePayPeriod()
+func TestParsePayPeriod(t *testing.T) {
+	var tests = []struct {
+		input string
+		start string // expected start date, or "" if the period is invalid
+		end   string
+	}{
+		{"01 March – 31 March", "2024-03-01", "2024-03-31"},
+		{"1 Mar - 31 Mar", "2024-03-01", "2024-03-31"},
+		{"01 March 2023 – 31 March 2023", "2023-03-01", "2023-03-31"},
+		{"01 March – 31 March 2023", "2023-03-01", "2023-03-31"},
+		{"2023-07-01 – 2023-07-14", "2023-07-01", "2023-07-14"},
+		{"2023-07-01-2023-07-14", "2023-07-01", "2023-07-14"},
+		{"16 December – 15 January", "2024-12-16", "2025-01-15"},
+		{"29 Feb – 31 Mar", "2024-02-29", "2024-03-31"},
+		{"31 March 2023 – 01 March 2023", "", ""},
+		{"01 March 2023 – 01 March 2024", "", ""},
+		{"31 Fooember – 31 March", "", ""},
+		{"01 March", "", ""},
+	}
+
+	if _, err := ParsePayPeriod("01 March – 31 March", 0); err == nil {
+		t.Errorf("FAILED: ParsePayPeriod() should fail for a pay period without a year when no year is assumed")
+	}
+
+	for _, test := range tests {
+		got, err := ParsePayPeriod(test.input, 2024)
+		if test.start == "" {
+			if err == nil {
+				t.Errorf("FAILED: ParsePayPeriod(%s) should return an error", test.input)
+			}
+			continue
+		}
+
+		if err != nil || got.Start.Format("2006-01-02") != test.start || got.End.Format("2006-01-02") != test.end {
+			t.Errorf("FAILED: ParsePayPeriod(%s) = %v - %v, %v: expected <%s - %s>", test.input, got.Start, got.End, err, test.start, test.end)
+		}
+	}
+}
+
+// tests for DeriveFrequency() and pay frequency given as an input field
+func TestPayFrequency(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  PayFrequency
+		gross Money.Amount // gross income for a salary of $52,000
+	}{
+		{"01 March – 31 March", Monthly, Money.FromDollars(4333)},
+		{"15 March – 31 March,monthly", Monthly, Money.FromDollars(2376)},   // pro-rated: 17 of 31 days
+		{"15 March 2024 – 31 March 2024", Monthly, Money.FromDollars(2376)}, // part of a month, but not a semi-monthly period
+		{"16 March – 31 March,monthly", Monthly, Money.FromDollars(2237)},   // pro-rated: 16 of 31 days, not a semi-monthly period
+		{"04 March – 10 March,weekly", Weekly, Money.FromDollars(1000)},
+		{"04 March – 17 March,fortnightly", Fortnightly, Money.FromDollars(2000)},
+		{"16 Feb 2023 – 28 Feb 2023,semi-monthly", SemiMonthly, Money.FromDollars(2167)},
+		{"01 July – 30 September", Quarterly, Money.FromDollars(13000)},
+		{"01 March – 14 March,fortnightly", Fortnightly, Money.FromDollars(2000)},
+	}
+
+	for _, test := range tests {
+		prr, err := createPayrollRecord(strings.Split("Ryan,Chen,52000,10%,"+test.input, ","))
+		if err != nil {
+			t.Errorf("FAILED: error creating payroll record for <%s>: %v", test.input, err)
+			continue
+		}
+
+		if prr.Frequency != test.want || prr.GrossIncome() != test.gross {
+			t.Errorf("FAILED: pay frequency for <%s> = %s, gross %s: expected <%s, %s>", test.input, prr.Frequency, prr.GrossIncome(), test.want, test.gross)
+		}
+	}
+
+	if _, err := createPayrollRecord(strings.Split("Ryan,Chen,52000,10%,01 April – 31 May,quarterly", ",")); err != nil {
+		t.Errorf("FAILED: a pay period within a quarter should be accepted: %v", err)
+	}
+
+	if _, err := createPayrollRecord(strings.Split("Ryan,Chen,52000,10%,01 March – 31 May,quarterly", ",")); err == nil {
+		t.Errorf("FAILED: a pay period spanning quarters should be rejected")
+	}
+
+	// a semi-monthly period could be part of a month, and a period running over a month end other than a full quarter could be anything
+	for _, period := range []string{"01 March – 15 March", "16 March – 31 March", "25 March – 07 April", "01 April – 31 May"} {
+		if _, err := createPayrollRecord(strings.Split("Ryan,Chen,52000,10%,"+period, ",")); err == nil {
+			t.Errorf("FAILED: pay frequency of <%s> should have to be given", period)
+		}
+	}
+}
+
+// tests for PayrollRecord.ProrationFactor() with both pro-ration methods
+func TestProration(t *testing.T) {
+	var tests = []struct {
+		input   string
+		method  ProrationMethod
+		num     int64
+		den     int64
+		gross   Money.Amount // gross income for a salary of $62,400
+		tax     Money.Amount // tax payable for a salary of $62,400
+		monthly bool
+	}{
+		{"01 March 2024 – 31 March 2024", CalendarDays, 1, 1, Money.FromDollars(5200), Money.FromDollars(986), true},
+		{"15 March 2024 – 31 March 2024,monthly", CalendarDays, 17, 31, Money.FromDollars(2852), Money.FromDollars(540), true},
+		{"15 March 2024 – 31 March 2024,monthly", WorkingDays, 11, 21, Money.FromDollars(2724), Money.FromDollars(516), true},
+		{"04 March 2024 – 06 March 2024,weekly", CalendarDays, 3, 7, Money.FromDollars(514), 0, false},
+		{"04 March 2024 – 06 March 2024,weekly", WorkingDays, 3, 5, Money.FromDollars(720), 0, false},
+	}
+
+	taxTable, err := TaxBracket.ReadTaxTable("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	defer func() { Proration = CalendarDays }()
+
+	for _, test := range tests {
+		Proration = test.method
+		prr, err := createPayrollRecord(strings.Split("Ryan,Chen,62400,10%,"+test.input, ","))
+		if err != nil {
+			t.Errorf("FAILED: error creating payroll record for <%s>: %v", test.input, err)
+			continue
+		}
+
+		num, den := prr.ProrationFactor()
+		if num != test.num || den != test.den || prr.GrossIncome() != test.gross {
+			t.Errorf("FAILED: pro-ration of <%s> by %s = %d/%d, gross %s: expected <%d/%d, %s>", test.input, test.method, num, den, prr.GrossIncome(), test.num, test.den, test.gross)
+		}
+
+		if test.monthly {
+			if tax, err := prr.IncomeTax(taxTable); err != nil || tax != test.tax {
+				t.Errorf("FAILED: pro-rated tax of <%s> by %s = %s, %v: expected <%s>", test.input, test.method, tax, err, test.tax)
+			}
+		}
+	}
+
+	if _, err := createPayrollRecord(strings.Split("Ryan,Chen,62400,10%,01 March – 31 March,weekly", ",")); err == nil {
+		t.Errorf("FAILED: a month-long weekly pay period should be rejected")
+	}
+}
+
+// tests for PayrollRecord.GrossIncome()
+func TestGrossIncome(t *testing.T) {
+	tests := map[*PayrollRecord]Money.Amount{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = Money.FromDollars(5004)
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 Apr – 30 Apr", ","))
+	tests[prr] = Money.FromDollars(10000)
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = Money.FromDollars(70833)
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,32185,25%,01 Dec – 31 Dec", ","))
+	tests[prr] = Money.FromDollars(2682)
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,895642,25%,01 Jun – 30 Jun", ","))
+	tests[prr] = Money.FromDollars(74637)
+
+	// test
+	for prr, expected := range tests {
+		got := prr.GrossIncome()
+
+		if prr.Valid {
+			if got != expected {
+				t.Errorf("FAILED: PayrollRecord.GrossIncome() = %v: expected <%s>", got, expected)
+			}
+		} else {
+			t.Errorf("FAILED: invalid payroll record")
+		}
+	}
+}
+
+// tests for PayrollRecord.IncomeTax()
+func TestIncomeTax(t *testing.T) {
+	tests := map[*PayrollRecord]Money.Amount{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = Money.FromDollars(922)
+	// prr, _ = createPayrollRecord(strings.Split("David,Rudd,0,9%,01 March – 31 March", ","))
+	// tests[prr] = Money.FromDollars(0)
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 Apr – 30 Apr", ","))
+	tests[prr] = Money.FromDollars(2696)
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = Money.FromDollars(29671)
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,32185,25%,01 Dec – 31 Dec", ","))
+	tests[prr] = Money.FromDollars(221)
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,895642,25%,01 Jun – 30 Jun", ","))
+	tests[prr] = Money.FromDollars(31382)
+
+	// test
+	taxConfigFile := "TAX_CONFIG.csv"
+	taxTable, err := TaxBracket.ReadTaxTable(taxConfigFile)
+
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	for prr, expected := range tests {
+		got, err := prr.IncomeTax(taxTable)
+
+		if err == nil {
+			if prr.Valid {
+				if got != expected {
+					t.Errorf("FAILED: PayrollRecord.IncomeTax() = %v: expected <%s>", got, expected)
+				}
+			} else {
+				t.Errorf("FAILED: invalid payroll record")
+			}
+		} else {
+			t.Errorf("FAILED: error calculating income tax: %v", err)
+		}
+	}
+}
+
+// tests for PayrollRecord.NetIncome()
+func TestNetIncome(t *testing.T) {
+	tests := map[*PayrollRecord]Money.Amount{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = Money.FromDollars(4082)
+	// prr, _ = createPayrollRecord(strings.Split("David,Rudd,0,9%,01 March – 31 March", ","))
+	// tests[prr] = Money.FromDollars(0)
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 Apr – 30 Apr", ","))
+	tests[prr] = Money.FromDollars(7304)
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = Money.FromDollars(41162)
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,32185,25%,01 Dec – 31 Dec", ","))
+	tests[prr] = Money.FromDollars(2461)
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,895642,25%,01 Jun – 30 Jun", ","))
+	tests[prr] = Money.FromDollars(43255)
+
+	// test
+	taxConfigFile := "TAX_CONFIG.csv"
+	taxTable, err := TaxBracket.ReadTaxTable(taxConfigFile)
+
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	for prr, expected := range tests {
+		got, err := prr.NetIncome(taxTable)
+
+		if err == nil {
+			if prr.Valid {
+				if got != expected {
+					t.Errorf("FAILED: PayrollRecord.NetIncome() = %v: expected <%s>", got, expected)
+				}
+			} else {
+				t.Errorf("FAILED: invalid payroll record")
+			}
+		} else {
+			t.Errorf("FAILED: error calculating net income: %v", err)
+		}
+	}
+}
+
+// tests for PayrollRecord.SuperAmount()
+func TestSuperAmount(t *testing.T) {
+	tests := map[*PayrollRecord]Money.Amount{}
+
+	var prr *PayrollRecord
+	prr, _ = createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	tests[prr] = Money.FromDollars(450)
+	// prr, _ = createPayrollRecord(strings.Split("David,Rudd,0,9%,01 March – 31 March", ","))
+	// tests[prr] = Money.FromDollars(0)
+	prr, _ = createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 Apr – 30 Apr", ","))
+	tests[prr] = Money.FromDollars(1000)
+	prr, _ = createPayrollRecord(strings.Split("Marcus,Aurelius,850000,25%,01 May – 31 May", ","))
+	tests[prr] = Money.FromDollars(17708)
+	prr, _ = createPayrollRecord(strings.Split("Georgy,Zukhov,32185,25%,01 Dec – 31 Dec", ","))
+	tests[prr] = Money.FromDollars(671)
+	prr, _ = createPayrollRecord(strings.Split("John, Citizen,895642,25%,01 Jun – 30 Jun", ","))
+	tests[prr] = Money.FromDollars(18659)
+
+	// test
+	for prr, expected := range tests {
+		got, err := prr.SuperAmount(&TaxBracket.TaxTable{}) // a table with no super limits
+
+		if err == nil {
+			if prr.Valid {
+				if got != expected {
+					t.Errorf("FAILED: PayrollRecord.SuperAmount() = %v: expected <%s>", got, expected)
+				}
+			} else {
+				t.Errorf("FAILED: invalid payroll record")
+			}
+		} else {
+			t.Errorf("FAILED: error calculating super: %v", err)
+		}
+	}
+}
+
+// tests for PayrollRecord.Print()
+func TestPrint_PayrollRecord(t *testing.T) {
+
+}
+
+// tests for PayrollRecord.CreatePayrollRecord()
+func TestCreatePayrollRecord(t *testing.T) {
+
+}
+
+// test writeOutputFile(outFileName string, records []*PayrollRecord, registry *TaxBracket.Registry) error
+func TestWriteOutputFile(t *testing.T) {
+	taxTables, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"David,Rudd,60050,9%,01 March – 31 March", "Ryan,Chen,120000,10%,01 March – 31 March"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		records = append(records, prr)
+	}
+	records[0].FirstName = "David, Jr." // a name with a comma must be quoted
+
+	outFile := OutputFileName(filepath.Join(t.TempDir(), "v1.2", "input.csv"))
+	if !strings.HasSuffix(outFile, filepath.Join("v1.2", "input-out.csv")) {
+		t.Errorf("FAILED: OutputFileName() = %s: expected <.../v1.2/input-out.csv>", outFile)
+	}
+	os.Mkdir(filepath.Dir(outFile), 0755)
+
+	if err := WriteOutputFile(outFile, records, taxTables); err != nil {
+		t.Fatalf("FAILED: WriteOutputFile() = %v", err)
+	}
+
+	got, _ := os.ReadFile(outFile)
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super\n" +
+		"\"David, Jr. Rudd\",01 March – 31 March,5004.00,922.00,4082.00,450.00\n" +
+		"Ryan Chen,01 March – 31 March,10000.00,2696.00,7304.00,1000.00\n"
+	if string(got) != expected {
+		t.Errorf("FAILED: WriteOutputFile() wrote <%s>: expected <%s>", got, expected)
+	}
+
+	// existing output is only replaced when Overwrite is set
+	if err := WriteOutputFile(outFile, records[1:], taxTables); err == nil {
+		t.Errorf("FAILED: WriteOutputFile() should refuse to overwrite an existing file")
+	}
+
+	defer func() { Overwrite = false }()
+	Overwrite = true
+	if err := WriteOutputFile(outFile, records[1:], taxTables); err != nil {
+		t.Errorf("FAILED: WriteOutputFile() with Overwrite = %v", err)
+	}
+
+	if entries, _ := os.ReadDir(filepath.Dir(outFile)); len(entries) != 1 {
+		t.Errorf("FAILED: WriteOutputFile() left temporary files behind: %v", entries)
+	}
+}
+
+// test records with incomes in cents between whole dollar bracket limits are taxed, and records whose pay can't be worked out
+// are rejected rather than stopping the output
+func TestCentIncomes(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	var records []*PayrollRecord
+	for i, row := range []string{"David,Rudd,36000,9%,01 March – 31 March", "Ryan,Chen,37000.50,9%,01 March – 31 March", "Kim,Lee,10000,9%,01 March – 31 March"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		prr.Line = i + 1
+		records = append(records, prr)
+	}
+
+	// $36,000 and a $83.37 monthly allowance taxed as $37,000.44 a year
+	records[0].Earnings = []*Earnings{{Employee: "David Rudd", Type: Allowance, Description: "Tools", Amount: Money.Amount(8337), Taxable: true, Super: true}}
+
+	// Kim Lee's pay can't be worked out from a table with no bracket for $10,000
+	table := *registry.Tables[0]
+	table.Brackets = table.Brackets[1:]
+	gappy, _ := TaxBracket.NewRegistry(&table)
+
+	if report := CheckTaxTables("input.csv", records, gappy); len(report) != 1 || report[0].Line != 3 || records[2].Valid {
+		t.Errorf("FAILED: CheckTaxTables() reported %v: expected Kim Lee's record on line 3 rejected", report)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, gappy); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Additional Earnings\n" +
+		"David Rudd,01 March – 31 March,3083.37,298.00,2785.00,278.00,83.37\n" +
+		"Ryan Chen,01 March – 31 March,3083.00,298.00,2785.00,277.00,0.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+}
+
+// test WriteOutput() in JSON and JSON Lines formats
+func TestWriteOutputJSON(t *testing.T) {
+	taxTables, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	valid, _ := createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March 2024 – 31 March 2024", ","))
+	invalid, _ := createPayrollRecord(strings.Split("Ryan,Chen,abc,9%,01 March – 31 March", ","))
+	records := []*PayrollRecord{valid, invalid}
+
+	defer func() { OutputFormat = CSV }()
+	OutputFormat = JSON
+
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, taxTables); err != nil {
+		t.Fatalf("FAILED: WriteOutput(JSON) = %v", err)
+	}
+
+	var got []OutputRecord
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != 2 {
+		t.Fatalf("FAILED: WriteOutput(JSON) wrote unreadable output <%s>: %v", buf.String(), err)
+	}
+
+	p := got[0].Payslip
+	if !got[0].Valid || p == nil || p.Name != "David Rudd" || p.PeriodStart != "2024-03-01" || p.Gross != Money.FromDollars(5004) ||
+		p.Tax != Money.FromDollars(922) || p.Net != Money.FromDollars(4082) || p.Super != Money.FromDollars(450) || p.Bracket.Percent != 325000 {
+		t.Errorf("FAILED: WriteOutput(JSON) valid record = %+v, payslip %+v", got[0], p)
+	}
+
+	if got[1].Valid || got[1].Payslip != nil || len(got[1].Errors) != 1 || got[1].Errors[0].Field != "salary" {
+		t.Errorf("FAILED: WriteOutput(JSON) invalid record = %+v", got[1])
+	}
+
+	OutputFormat = JSONLines
+	buf.Reset()
+	if err := WriteOutput(&buf, records, taxTables); err != nil || strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("FAILED: WriteOutput(JSON Lines) = %v, wrote <%s>: expected two lines", err, buf.String())
+	}
+}
+
+// test TaxBracket.Registry selection of the tax table in force for a pay period
+func TestRegistry(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"2023.json": `{"jurisdiction": "AU", "financial_year": "2022-23", "effective_from": "2022-07-01", "effective_to": "2023-06-30",
+			"brackets": [{"lower": 0, "upper": 18200, "percent": 0}, {"lower": 18201, "percent": 10, "above": 18200}]}`,
+		"2024.json": `{"jurisdiction": "AU", "financial_year": "2023-24", "effective_from": "2023-07-01", "effective_to": "2024-06-30",
+			"brackets": [{"lower": 0, "upper": 18200, "percent": 0}, {"lower": 18201, "percent": 20, "above": 18200}]}`,
+		"overlap.json": `{"effective_from": "2024-01-01", "brackets": [{"lower": 0, "percent": 0}]}`,
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	registry, err := TaxBracket.ReadRegistry(filepath.Join(dir, "2023.json"), filepath.Join(dir, "2024.json"))
+	if err != nil {
+		t.Fatalf("FAILED: TaxBracket.ReadRegistry() = %v", err)
+	}
+
+	var tests = []struct {
+		period string
+		table  string
+		tax    Money.Amount // tax for a salary of $30,200 per month
+	}{
+		{"01 June 2023 – 30 June 2023", "AU 2022-23", Money.FromDollars(100)},
+		{"01 July 2023 – 31 July 2023", "AU 2023-24", Money.FromDollars(200)},
+	}
+
+	for _, test := range tests {
+		prr, _ := createPayrollRecord(strings.Split("Ryan,Chen,30200,10%,"+test.period, ","))
+		payslip, err := prr.Payslip(registry)
+		if err != nil || payslip.TaxTable != test.table || payslip.Tax != test.tax {
+			t.Errorf("FAILED: Payslip(%s) = %+v, %v: expected table <%s> and tax <%s>", test.period, payslip, err, test.table, test.tax)
+		}
+	}
+
+	prr, _ := createPayrollRecord(strings.Split("Ryan,Chen,30200,10%,01 July 2024 – 31 July 2024", ","))
+	if _, err := prr.Payslip(registry); err == nil {
+		t.Errorf("FAILED: Payslip() should fail when no tax table is in force for the pay period")
+	}
+
+	if _, err := TaxBracket.ReadRegistry(filepath.Join(dir, "2024.json"), filepath.Join(dir, "overlap.json")); err == nil {
+		t.Errorf("FAILED: TaxBracket.ReadRegistry() should reject overlapping tax tables")
+	}
+}
+
+// test TaxBracket.Levy calculation and levies in PayrollRecord.NetIncome()
+func TestLevies(t *testing.T) {
+	medicare := &TaxBracket.Levy{Name: "Medicare Levy", Percent: 20000, Threshold: Money.FromDollars(24000), ShadeIn: 100000}
+	surcharge := &TaxBracket.Levy{Name: "Surcharge", Percent: 10000, Threshold: Money.FromDollars(90000)}
+
+	var tests = []struct {
+		levy   *TaxBracket.Levy
+		income Money.Amount
+		want   Money.Amount
+	}{
+		{medicare, Money.FromDollars(20000), 0},                       // below threshold
+		{medicare, Money.FromDollars(25000), Money.FromDollars(100)},  // shading in: 10% of $1,000 above threshold
+		{medicare, Money.FromDollars(60000), Money.FromDollars(1200)}, // fully phased in: 2% of income
+		{surcharge, Money.FromDollars(90000), 0},
+		{surcharge, Money.FromDollars(120000), Money.FromDollars(1200)},
+	}
+
+	for _, test := range tests {
+		if got := test.levy.AnnualLevy(test.income).Round(Money.Rounding{Precision: Money.Cents}); got != test.want {
+			t.Errorf("FAILED: %s.AnnualLevy(%s) = %s: expected <%s>", test.levy.Name, test.income, got, test.want)
+		}
+	}
+
+	taxTable, err := TaxBracket.ReadTaxTable("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+	taxTable.Levies = []*TaxBracket.Levy{medicare, surcharge}
+
+	// $120,000: tax $2,696, Medicare levy $200, surcharge $100 a month
+	prr, _ := createPayrollRecord(strings.Split("Ryan,Chen,120000,10%,01 March – 31 March", ","))
+	levies := prr.Levies(taxTable)
+	if len(levies) != 2 || levies[0] != (LevyAmount{"Medicare Levy", Money.FromDollars(200)}) || levies[1] != (LevyAmount{"Surcharge", Money.FromDollars(100)}) {
+		t.Errorf("FAILED: PayrollRecord.Levies() = %v", levies)
+	}
+
+	if net, err := prr.NetIncome(taxTable); err != nil || net != Money.FromDollars(7004) {
+		t.Errorf("FAILED: PayrollRecord.NetIncome() with levies = %s, %v: expected <7004.00>", net, err)
+	}
+}
+
+// test TaxBracket.Offset phase-out and offsets in PayrollRecord.IncomeTax()
+func TestOffsets(t *testing.T) {
+	lito := &TaxBracket.Offset{Name: "LITO", Amount: Money.FromDollars(700), PhaseOut: []*TaxBracket.PhaseOut{
+		{From: Money.FromDollars(37500), To: Money.FromDollars(45000), Percent: 50000},
+		{From: Money.FromDollars(45000), To: Money.FromDollars(66667), Percent: 15000},
+	}}
+
+	var tests = []struct {
+		income Money.Amount
+		want   Money.Amount
+	}{
+		{Money.FromDollars(30000), Money.FromDollars(700)}, // full offset
+		{Money.FromDollars(40000), Money.FromDollars(575)}, // less 5% of $2,500
+		{Money.FromDollars(50000), Money.FromDollars(250)}, // less 5% of $7,500 and 1.5% of $5,000
+		{Money.FromDollars(70000), 0},                      // phased out completely
+	}
+
+	for _, test := range tests {
+		if got := lito.AnnualOffset(test.income).Round(Money.Rounding{Precision: Money.Cents}); got != test.want {
+			t.Errorf("FAILED: AnnualOffset(%s) = %s: expected <%s>", test.income, got, test.want)
+		}
+	}
+
+	taxTable, err := TaxBracket.ReadTaxTable("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+	taxTable.Offsets = []*TaxBracket.Offset{lito}
+
+	var recordTests = []struct {
+		input  string
+		tax    Money.Amount
+		offset Money.Amount
+		net    Money.Amount
+	}{
+		{"Ryan,Chen,30000,10%,01 March – 31 March", Money.FromDollars(129), Money.FromDollars(58), Money.FromDollars(2371)}, // $187 bracket tax less $58 offset
+		{"Ryan,Chen,20000,10%,01 March – 31 March", 0, Money.FromDollars(29), Money.FromDollars(1667)},                      // offset limited to the $29 bracket tax
+	}
+
+	for _, test := range recordTests {
+		prr, _ := createPayrollRecord(strings.Split(test.input, ","))
+
+		tax, err := prr.IncomeTax(taxTable)
+		if err != nil || tax != test.tax {
+			t.Errorf("FAILED: IncomeTax() of <%s> = %s, %v: expected <%s>", test.input, tax, err, test.tax)
+		}
+
+		offsets, err := prr.Offsets(taxTable)
+		if err != nil || len(offsets) != 1 || offsets[0] != (OffsetAmount{"LITO", test.offset}) {
+			t.Errorf("FAILED: Offsets() of <%s> = %v, %v: expected <%s>", test.input, offsets, err, test.offset)
+		}
+
+		if net, err := prr.NetIncome(taxTable); err != nil || net != test.net {
+			t.Errorf("FAILED: NetIncome() of <%s> = %s, %v: expected <%s>", test.input, net, err, test.net)
+		}
+	}
+}
+
+// test named tax scales: each record taxed on its own scale, with levies it is exempt from left out
+func TestTaxScales(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tax.yaml")
+	os.WriteFile(file, []byte("brackets:\n"+
+		"  - {lower: 0, upper: 18200, percent: 0}\n"+
+		"  - {lower: 18201, percent: 19, above: 18200}\n"+
+		"levies:\n"+
+		"  - {name: Medicare Levy, percent: 2}\n"+
+		"scales:\n"+
+		"  - name: Non-Resident\n"+
+		"    exempt: [Medicare Levy]\n"+
+		"    brackets:\n"+
+		"      - {lower: 0, percent: 32.5}\n"), 0644)
+
+	taxTable, err := TaxBracket.ReadTaxTable(file)
+	if err != nil {
+		t.Fatalf("FAILED: TaxBracket.ReadTaxTable() = %v", err)
+	}
+
+	var tests = []struct {
+		input  string
+		tax    Money.Amount
+		levies int
+		net    Money.Amount
+	}{
+		{"Ryan,Chen,60000,10%,01 March – 31 March", Money.FromDollars(662), 1, Money.FromDollars(4238)},                       // default scale, plus $100 Medicare levy
+		{"Ryan,Chen,60000,10%,01 March – 31 March,,resident", Money.FromDollars(662), 1, Money.FromDollars(4238)},             // default scale by name
+		{"Ryan,Chen,60000,10%,01 March – 31 March,monthly,non-resident", Money.FromDollars(1625), 0, Money.FromDollars(3375)}, // no tax-free threshold, no levy
+	}
+
+	for _, test := range tests {
+		prr, err := createPayrollRecord(strings.Split(test.input, ","))
+		if err != nil {
+			t.Errorf("FAILED: createPayrollRecord(%s) = %v", test.input, err)
+			continue
+		}
+
+		tax, err := prr.IncomeTax(taxTable)
+		if err != nil || tax != test.tax {
+			t.Errorf("FAILED: IncomeTax() of <%s> = %s, %v: expected <%s>", test.input, tax, err, test.tax)
+		}
+
+		if levies := prr.Levies(taxTable); len(levies) != test.levies {
+			t.Errorf("FAILED: Levies() of <%s> = %v: expected %d levies", test.input, levies, test.levies)
+		}
+
+		if net, err := prr.NetIncome(taxTable); err != nil || net != test.net {
+			t.Errorf("FAILED: NetIncome() of <%s> = %s, %v: expected <%s>", test.input, net, err, test.net)
+		}
+	}
+
+	// a scale the tax table doesn't define makes the record invalid
+	registry, _ := TaxBracket.NewRegistry(taxTable)
+	prr, _ := createPayrollRecord(strings.Split("Ryan,Chen,60000,10%,01 March – 31 March,,working holiday", ","))
+	prr.Line = 2
+	report := CheckTaxTables("input.csv", []*PayrollRecord{prr}, registry)
+	if len(report) != 1 || report[0].Field != "scale" || report[0].Line != 2 || prr.Valid {
+		t.Errorf("FAILED: CheckTaxTables() with unknown scale = %v, record valid %v", report, prr.Valid)
+	}
+
+	// scales may only be exempted from the table's own levies and offsets
+	os.WriteFile(file, []byte("brackets:\n  - {lower: 0, percent: 0}\nscales:\n  - {name: whm, exempt: [LITO], brackets: [{lower: 0, percent: 15}]}\n"), 0644)
+	if _, err := TaxBracket.ReadTaxTable(file); err == nil {
+		t.Errorf("FAILED: TaxBracket.ReadTaxTable() accepted a scale exempt from an undefined offset")
+	}
+}
+
+// test study loan repayment bands and withholding for employees with a study loan
+func TestStudyLoan(t *testing.T) {
+	taxTable, err := TaxBracket.ReadTaxTable("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+	taxTable.StudyLoan = []*TaxBracket.RepaymentBand{
+		{Lower: Money.FromDollars(51550), Upper: Money.FromDollars(59518), Percent: 10000},
+		{Lower: Money.FromDollars(59519), Upper: Money.FromDollars(63089), Percent: 20000},
+		{Lower: Money.FromDollars(63090), Percent: 25000},
+	}
+
+	var tests = []struct {
+		income Money.Amount
+		want   Money.Amount
+	}{
+		{Money.FromDollars(50000), 0},                       // below the repayment threshold
+		{Money.FromDollars(55000), Money.FromDollars(550)},  // 1% of the whole income
+		{Money.FromDollars(60050), Money.FromDollars(1201)}, // 2% of the whole income
+		{Money.FromDollars(120000), Money.FromDollars(3000)},
+	}
+
+	for _, test := range tests {
+		if got := taxTable.AnnualRepayment(test.income).Round(Money.Rounding{Precision: Money.Cents}); got != test.want {
+			t.Errorf("FAILED: AnnualRepayment(%s) = %s: expected <%s>", test.income, got, test.want)
+		}
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"David,Rudd,60050,9%,01 March – 31 March,,,yes", "Ryan,Chen,120000,10%,01 March – 31 March,,,no"} {
+		prr, err := createPayrollRecord(strings.Split(row, ","))
+		if err != nil {
+			t.Fatalf("FAILED: createPayrollRecord(%s) = %v", row, err)
+		}
+		records = append(records, prr)
+	}
+
+	if got := records[0].StudyLoanRepayment(taxTable); got != Money.FromDollars(100) {
+		t.Errorf("FAILED: StudyLoanRepayment() = %s: expected <100.00>", got)
+	}
+
+	if got := records[1].StudyLoanRepayment(taxTable); got != 0 {
+		t.Errorf("FAILED: StudyLoanRepayment() without a study loan = %s: expected <0.00>", got)
+	}
+
+	// the repayment has its own output column and comes out of net income
+	registry, _ := TaxBracket.NewRegistry(taxTable)
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Study Loan\n" +
+		"David Rudd,01 March – 31 March,5004.00,922.00,3982.00,450.00,100.00\n" +
+		"Ryan Chen,01 March – 31 March,10000.00,2696.00,7304.00,1000.00,0.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+
+	if _, err := createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March,,,maybe", ",")); err == nil {
+		t.Errorf("FAILED: createPayrollRecord() accepted an invalid study loan flag")
+	}
+}
+
+// test the maximum contribution base and concessional contributions cap applied to super
+func TestSuperCap(t *testing.T) {
+	taxTable, err := TaxBracket.ReadTaxTable("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	// maximum contribution base of $15,000 a quarter: super on no more than $5,000 a month
+	taxTable.Super.MaxContributionBase = Money.FromDollars(15000)
+	for input, expected := range map[string]Money.Amount{
+		"Ryan,Chen,120000,10%,01 March – 31 March":              Money.FromDollars(500),
+		"Ryan,Chen,120000,10%,01 March – 15 March,semi-monthly": Money.FromDollars(250),
+		"David,Rudd,48000,9%,01 March – 31 March":               Money.FromDollars(360),
+	} {
+		prr, _ := createPayrollRecord(strings.Split(input, ","))
+		if got, err := prr.SuperAmount(taxTable); err != nil || got != expected {
+			t.Errorf("FAILED: SuperAmount() of <%s> = %s, %v: expected <%s>", input, got, err, expected)
+		}
+	}
+
+	// concessional cap of $1,200 reached by the second of three $1,000 contributions for the same employee
+	taxTable.Super.MaxContributionBase = 0
+	taxTable.Super.ConcessionalCap = Money.FromDollars(1200)
+	registry, _ := TaxBracket.NewRegistry(taxTable)
+
+	defer func() { SuperCap = WarnOverCap }()
+	for _, test := range []struct {
+		policy   CapPolicy
+		expected []Money.Amount
+	}{
+		{WarnOverCap, []Money.Amount{Money.FromDollars(1000), Money.FromDollars(450), Money.FromDollars(1000), Money.FromDollars(1000)}},
+		{LimitToCap, []Money.Amount{Money.FromDollars(1000), Money.FromDollars(450), Money.FromDollars(200), 0}},
+	} {
+		SuperCap = test.policy
+
+		var records []*PayrollRecord
+		for i, row := range []string{"Ryan,Chen,120000,10%,01 March – 31 March", "David,Rudd,60050,9%,01 March – 31 March",
+			"Ryan,Chen,120000,10%,01 April – 30 April", "Ryan,Chen,120000,10%,01 May – 31 May"} {
+			prr, _ := createPayrollRecord(strings.Split(row, ","))
+			prr.Line = i + 1
+			records = append(records, prr)
+		}
+
+		warnings := TrackConcessionalCap(records, registry)
+		if len(warnings) != 2 {
+			t.Errorf("FAILED: TrackConcessionalCap() with policy %s warned %v: expected 2 warnings", test.policy, warnings)
+		}
+
+		for i, rec := range records {
+			if got, _ := rec.SuperAmount(taxTable); got != test.expected[i] {
+				t.Errorf("FAILED: SuperAmount() of record %d with policy %s = %s: expected <%s>", i+1, test.policy, got, test.expected[i])
+			}
+		}
+	}
+
+	// salary sacrifice of $2,000 a month is paid in full under LimitToCap, having been deducted from pay: only the employer's
+	// $1,000 is limited, first to the $500 left under a $2,500 cap and then to nothing, with the sacrifice alone over the cap
+	SuperCap = LimitToCap
+	taxTable.Super.ConcessionalCap = Money.FromDollars(2500)
+
+	var records []*PayrollRecord
+	for i, row := range []string{"Ryan,Chen,120000,10%,01 March – 31 March", "Ryan,Chen,120000,10%,01 April – 30 April"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		prr.Line = i + 1
+		prr.Deductions = []*Deduction{{Employee: "Ryan Chen", Type: SalarySacrifice, Description: "Super", Amount: Money.FromDollars(24000), Annual: true}}
+		records = append(records, prr)
+	}
+
+	if warnings := TrackConcessionalCap(records, registry); len(warnings) != 3 {
+		t.Errorf("FAILED: TrackConcessionalCap() with salary sacrifice warned %v: expected 3 warnings", warnings)
+	}
+
+	for i, expected := range []Money.Amount{Money.FromDollars(2500), Money.FromDollars(2000)} {
+		if got, _ := records[i].SuperAmount(taxTable); got != expected {
+			t.Errorf("FAILED: SuperAmount() of record %d with salary sacrifice = %s: expected <%s>", i+1, got, expected)
+		}
+	}
+
+	if got := records[1].SalarySacrifice(); got != Money.FromDollars(2000) {
+		t.Errorf("FAILED: SalarySacrifice() under LimitToCap = %s: expected <%s>", got, Money.FromDollars(2000))
+	}
+
+	// a change of tax table part way through a financial year carries the contributions over: $1,000 in December and $1,000
+	// in January against a $1,500 cap limits January's to $500
+	taxTable.Super.ConcessionalCap = Money.FromDollars(1500)
+	firstHalf, secondHalf := *taxTable, *taxTable
+	firstHalf.Jurisdiction, firstHalf.FinancialYear = "Australia", "2023-24"
+	firstHalf.EffectiveFrom, firstHalf.From = "2023-07-01", time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	firstHalf.EffectiveTo, firstHalf.To = "2023-12-31", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	secondHalf.Jurisdiction, secondHalf.FinancialYear = "Australia", "2023-24"
+	secondHalf.EffectiveFrom, secondHalf.From = "2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secondHalf.EffectiveTo, secondHalf.To = "2024-06-30", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	if registry, err = TaxBracket.NewRegistry(&firstHalf, &secondHalf); err != nil {
+		t.Fatalf("FAILED: NewRegistry() = %v", err)
+	}
+
+	records = nil
+	for i, row := range []string{"Ryan,Chen,120000,10%,01 December 2023 – 31 December 2023", "Ryan,Chen,120000,10%,01 January 2024 – 31 January 2024"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		prr.Line = i + 1
+		records = append(records, prr)
+	}
+
+	if warnings := TrackConcessionalCap(records, registry); len(warnings) != 1 {
+		t.Errorf("FAILED: TrackConcessionalCap() across a mid-year change of tax table warned %v: expected 1 warning", warnings)
+	}
+
+	for i, expected := range []Money.Amount{Money.FromDollars(1000), Money.FromDollars(500)} {
+		table, _ := records[i].TaxTable(registry)
+		if got, _ := records[i].SuperAmount(table); got != expected {
+			t.Errorf("FAILED: SuperAmount() of record %d across a mid-year change of tax table = %s: expected <%s>", i+1, got, expected)
+		}
+	}
+}
+
+// test pre-tax deductions and salary sacrifice read from a deductions file
+func TestDeductions(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	deductionsFile := filepath.Join(t.TempDir(), "deductions.csv")
+	os.WriteFile(deductionsFile, []byte("Employee,Type,Description,Amount,Per\n"+
+		"ryan  chen,sacrifice,Super,12000,year\n"+
+		"Ryan Chen,pre-tax,Novated lease,500,period\n"+
+		"Ryan Chen,after-tax,Union fees,100,period\n"+
+		"Nobody,pre-tax,Novated lease,6000,year\n"), 0644)
+
+	deductions, report, err := ReadDeductions(deductionsFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadDeductions() = %v", err)
+	}
+
+	if len(report) != 1 || report[0].Line != 4 || report[0].Field != "type" {
+		t.Errorf("FAILED: ReadDeductions() reported %v: expected an unknown type on line 4", report)
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"Ryan,Chen,120000,10%,01 March – 31 March", "David,Rudd,60050,9%,01 March – 31 March"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		records = append(records, prr)
+	}
+
+	report = AttachDeductions(deductionsFile, records, deductions)
+	if len(report) != 1 || report[0].Line != 5 || report[0].Field != "employee" {
+		t.Errorf("FAILED: AttachDeductions() reported %v: expected an unknown employee on line 5", report)
+	}
+
+	// taxed on $102,000 after $18,000 of pre-tax deductions, with $1,000 a month sacrificed into super
+	if got := records[0].TaxableIncome(); got != Money.FromDollars(102000) {
+		t.Errorf("FAILED: TaxableIncome() = %s: expected <102000.00>", got)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Pre-Tax Deductions,Salary Sacrifice\n" +
+		"Ryan Chen,01 March – 31 March,10000.00,2141.00,6359.00,2000.00,1500.00,1000.00\n" +
+		"David Rudd,01 March – 31 March,5004.00,922.00,4082.00,450.00,0.00,0.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+
+	// pre-tax deductions can't be more than the salary
+	deductions = map[string][]*Deduction{"david rudd": {{Employee: "David Rudd", Type: PreTax, Amount: Money.FromDollars(70000), Annual: true, Line: 2}}}
+	if report := AttachDeductions(deductionsFile, records[1:], deductions); len(report) != 1 || records[1].Valid {
+		t.Errorf("FAILED: AttachDeductions() accepted deductions larger than the salary: %v", report)
+	}
+
+	// Ryan Chen's union fees row is invalid, so their record is rejected rather than paid without it
+	_, report, _ = ReadDeductions(deductionsFile)
+	if rejected := RejectEmployees("input.csv", records, report); len(rejected) != 1 || records[0].Valid {
+		t.Errorf("FAILED: RejectEmployees() = %v: expected Ryan Chen's record rejected for the invalid row on line 4", rejected)
+	}
+
+	// an amount must say whether it is a year's or each pay period's
+	os.WriteFile(deductionsFile, []byte("Employee,Type,Description,Amount\nRyan Chen,pre-tax,Novated lease,500\n"), 0644)
+	if _, report, _ := ReadDeductions(deductionsFile); len(report) != 1 || report[0].Field != "per" || report[0].Employee != "Ryan Chen" {
+		t.Errorf("FAILED: ReadDeductions() reported %v: expected a missing per on line 2", report)
+	}
+}
+
+// test post-tax deductions taken from net pay in priority order, with a garnishee's protected earnings left untouched
+func TestPostTaxDeductions(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	deductionsFile := filepath.Join(t.TempDir(), "deductions.csv")
+	os.WriteFile(deductionsFile, []byte("Employee,Type,Description,Amount,Per,Percent,Priority,Protected\n"+
+		"David Rudd,post-tax,Union fees,20,period,,2,\n"+
+		"David Rudd,garnishee,Court order,5000,period,,3,3000\n"+
+		"David Rudd,percent,Child support,,,10%,1,\n"+
+		"David Rudd,percent,Too much,,,150%,,\n"+
+		"David Rudd,garnishee,Negative,100,period,,,-5\n"+
+		"David Rudd,post-tax,Parking,240,year,,1,\n"), 0644)
+
+	deductions, report, err := ReadDeductions(deductionsFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadDeductions() = %v", err)
+	}
+
+	if len(report) != 2 || report[0].Field != "percent" || report[1].Field != "protected" {
+		t.Errorf("FAILED: ReadDeductions() reported %v: expected an invalid percent and protected earnings", report)
+	}
+
+	prr, _ := createPayrollRecord(strings.Split("David,Rudd,60050,9%,01 March – 31 March", ","))
+	records := []*PayrollRecord{prr}
+	if report := AttachDeductions(deductionsFile, records, deductions); len(report) != 0 {
+		t.Errorf("FAILED: AttachDeductions() = %v", report)
+	}
+
+	// $4,082 net pay: 10% child support and $240 a year of parking first, then union fees, then the garnishee leaving $3,000 protected
+	taxTable := registry.Tables[0]
+	got, err := prr.PostTaxDeductions(taxTable)
+	expected := []DeductionAmount{{"Child support", "percent", Money.FromDollars(408)}, {"Parking", "post-tax", Money.FromDollars(20)},
+		{"Union fees", "post-tax", Money.FromDollars(20)}, {"Court order", "garnishee", Money.FromDollars(634)}}
+	if err != nil || len(got) != len(expected) {
+		t.Fatalf("FAILED: PostTaxDeductions() = %v, %v: expected <%v>", got, err, expected)
+	}
+
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("FAILED: PostTaxDeductions()[%d] = %v: expected <%v>", i, got[i], expected[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	output := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Post-Tax Deductions\n" +
+		"David Rudd,01 March – 31 March,5004.00,922.00,3000.00,450.00,1082.00\n"
+	if buf.String() != output {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), output)
+	}
+}
+
+// test earnings lines paid on top of salary, flowing through gross income, tax and super
+func TestEarnings(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	earningsFile := filepath.Join(t.TempDir(), "earnings.csv")
+	os.WriteFile(earningsFile, []byte("Employee,Type,Description,Amount,Hours,Rate,Taxable,Super,Period\n"+
+		"Ryan Chen,bonus,Q1 bonus,1200,,,,,01 March – 31 March\n"+
+		"Ryan Chen,overtime,Saturday,,10,75.50,,,01 March – 31 March\n"+
+		"Ryan Chen,allowance,Meal,45,,,no,no,1 Mar - 31 Mar\n"+
+		"David Rudd,allowance,Tools,20,,,,,\n"+
+		"Ryan Chen,bonus,Which period?,100,,,,,\n"+
+		"Ryan Chen,commission,No rate,,10,,,,\n"), 0644)
+
+	earnings, report, err := ReadEarnings(earningsFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadEarnings() = %v", err)
+	}
+
+	if len(report) != 1 || report[0].Line != 7 || report[0].Field != "rate" {
+		t.Errorf("FAILED: ReadEarnings() reported %v: expected a missing rate on line 7", report)
+	}
+
+	if len(earnings) != 5 || earnings[1].Amount != Money.FromDollars(755) || earnings[1].Super || !earnings[1].Taxable {
+		t.Fatalf("FAILED: ReadEarnings() read %d lines: expected 5 with 10 hours at 75.50 overtime not counting towards super", len(earnings))
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"Ryan,Chen,120000,10%,01 March – 31 March", "Ryan,Chen,120000,10%,01 April – 30 April", "David,Rudd,60050,9%,01 March – 31 March"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		records = append(records, prr)
+	}
+
+	// an earnings line without a pay period is only matched to an employee with one record
+	report = AttachEarnings(earningsFile, records, earnings)
+	if len(report) != 1 || report[0].Line != 6 || report[0].Field != "period" {
+		t.Errorf("FAILED: AttachEarnings() reported %v: expected an ambiguous pay period on line 6", report)
+	}
+
+	// March: $1,955 of taxable earnings taxed as if paid every month, super on salary and the bonus only
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Additional Earnings\n" +
+		"Ryan Chen,01 March – 31 March,12000.00,3419.00,8581.00,1120.00,2000.00\n" +
+		"Ryan Chen,01 April – 30 April,10000.00,2696.00,7304.00,1000.00,0.00\n" +
+		"David Rudd,01 March – 31 March,5024.00,928.00,4096.00,452.00,20.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+}
+
+// test hourly employees paid from a timesheet file, with penalty rates for weekends and public holidays
+func TestTimesheet(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	if Penalties, err = ParsePenaltyRates("saturday=1.5,holiday=2.5"); err != nil {
+		t.Fatalf("FAILED: ParsePenaltyRates() = %v", err)
+	}
+	PublicHolidays = map[time.Time]string{time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC): "Labour Day"}
+	defer func() {
+		Penalties, _ = ParsePenaltyRates("")
+		PublicHolidays = map[time.Time]string{}
+	}()
+
+	if _, err := ParsePenaltyRates("sunday=0.5"); err == nil {
+		t.Errorf("FAILED: ParsePenaltyRates() accepted a penalty rate below 1")
+	}
+
+	// an employee gives either an annual salary or an hourly rate
+	if _, err := createPayrollRecord(strings.Split("Sam,Ng,50000,9%,04 March 2024 – 10 March 2024,weekly,,,25", ",")); err == nil {
+		t.Errorf("FAILED: createPayrollRecord() accepted both an annual salary and an hourly rate")
+	}
+
+	timesheetFile := filepath.Join(t.TempDir(), "timesheet.csv")
+	os.WriteFile(timesheetFile, []byte("Employee,Date,Hours,Rate\n"+
+		"Kim Lee,2024-03-04,8,\n"+
+		"Kim Lee,2024-03-05,7.5,\n"+
+		"Kim Lee,2024-03-09,4,\n"+
+		"Kim Lee,2024-03-12,8,\n"+
+		"David Rudd,2024-03-05,8,\n"+
+		"Kim Lee,2024-03-06,25,\n"), 0644)
+
+	lines, report, err := ReadTimesheet(timesheetFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadTimesheet() = %v", err)
+	}
+
+	if len(lines) != 5 || len(report) != 1 || report[0].Line != 7 || report[0].Field != "hours" {
+		t.Fatalf("FAILED: ReadTimesheet() read %d lines and reported %v: expected 5 lines and too many hours on line 7", len(lines), report)
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"Kim,Lee,,9%,04 March 2024 – 10 March 2024,weekly,,,30", "David,Rudd,60050,9%,04 March 2024 – 10 March 2024,weekly"} {
+		prr, err := createPayrollRecord(strings.Split(row, ","))
+		if err != nil {
+			t.Fatalf("FAILED: createPayrollRecord(%s) = %v", row, err)
+		}
+		records = append(records, prr)
+	}
+
+	// hours outside the pay period, and hours for a salaried employee without a rate, are reported
+	report = AttachTimesheet(timesheetFile, records, lines)
+	if len(report) != 2 || report[0].Line != 5 || report[0].Field != "employee" || report[1].Line != 6 || report[1].Field != "rate" {
+		t.Errorf("FAILED: AttachTimesheet() reported %v: expected lines 5 and 6", report)
+	}
+
+	// Kim Lee: 8 hours on a public holiday at 2.5 times, 7.5 on a weekday and 4 on a Saturday at 1.5 times: $1,005 for 19.5 hours
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Hours Worked,Timesheet Pay\n" +
+		"Kim Lee,04 March 2024 – 10 March 2024,1005.00,164.00,841.00,90.00,19.5,1005.00\n" +
+		"David Rudd,04 March 2024 – 10 March 2024,1155.00,213.00,942.00,104.00,0,0.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+
+	// an hourly employee's pre-tax deductions are checked against the week's pay, not their (zero) annual salary:
+	// $100 a week is taken from $1,005, but $1,200 a week is more than it
+	deductionsFile := filepath.Join(t.TempDir(), "deductions.csv")
+	for amount, valid := range map[Money.Amount]bool{Money.FromDollars(5200): true, Money.FromDollars(62400): false} {
+		records[0].Valid = true
+		deductions := map[string][]*Deduction{"kim lee": {{Employee: "Kim Lee", Type: PreTax, Description: "Novated lease", Amount: amount, Annual: true, Line: 2}}}
+		if report := AttachDeductions(deductionsFile, records[:1], deductions); records[0].Valid != valid || (len(report) == 0) != valid {
+			t.Errorf("FAILED: AttachDeductions() of %s a year to an hourly employee reported %v: expected valid %v", amount, report, valid)
+		}
+	}
+}
+
+// test marginal tax withholding on bonuses and other irregular earnings lines
+func TestMarginalWithholding(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	earningsFile := filepath.Join(t.TempDir(), "earnings.csv")
+	os.WriteFile(earningsFile, []byte("Employee,Type,Description,Amount,Period,Method\n"+
+		"Ryan Chen,bonus,Annual bonus,12000,01 March – 31 March,marginal\n"+
+		"Ryan Chen,bonus,Annual bonus,12000,01 April – 30 April,\n"+
+		"Ryan Chen,bonus,Annual bonus,12000,01 May – 31 May,lump sum\n"), 0644)
+
+	earnings, report, err := ReadEarnings(earningsFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadEarnings() = %v", err)
+	}
+
+	if len(earnings) != 2 || earnings[0].Method != Marginal || earnings[1].Method != Annualised || len(report) != 1 || report[0].Field != "method" {
+		t.Fatalf("FAILED: ReadEarnings() read %d lines and reported %v: expected a marginal and an annualised line and an unknown method on line 4", len(earnings), report)
+	}
+
+	var records []*PayrollRecord
+	for _, row := range []string{"Ryan,Chen,120000,10%,01 March – 31 March", "Ryan,Chen,120000,10%,01 April – 30 April"} {
+		prr, _ := createPayrollRecord(strings.Split(row, ","))
+		records = append(records, prr)
+	}
+
+	if report := AttachEarnings(earningsFile, records, earnings); len(report) != 0 {
+		t.Fatalf("FAILED: AttachEarnings() reported %v", report)
+	}
+
+	// March: the bonus adds 37% of $12,000 to the year's tax, all withheld this month. April: the bonus is taxed as though paid every
+	// month, taking the annualised income to $264,000 and withholding $5,000 more
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Additional Earnings\n" +
+		"Ryan Chen,01 March – 31 March,22000.00,7136.00,14864.00,2200.00,12000.00\n" +
+		"Ryan Chen,01 April – 30 April,22000.00,7696.00,14304.00,2200.00,12000.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+}
+
+// test year-to-date totals carried between runs in a YTD store
+func TestYTDStore(t *testing.T) {
+	dir := t.TempDir()
+	taxFile := filepath.Join(dir, "tax.json")
+	os.WriteFile(taxFile, []byte(`{"jurisdiction": "Australia", "financial_year": "2023-24", "effective_from": "2023-07-01", "effective_to": "2024-06-30",
+		"brackets": [
+			{"lower": 0, "upper": 18200, "percent": 0, "lump": 0, "above": 0},
+			{"lower": 18201, "upper": 37000, "percent": 19, "lump": 0, "above": 18200},
+			{"lower": 37001, "upper": 80000, "percent": 32.5, "lump": 3572, "above": 37000},
+			{"lower": 80001, "upper": 180000, "percent": 37, "lump": 17547, "above": 80000},
+			{"lower": 180001, "percent": 45, "lump": 54547, "above": 180000}],
+		"levies": [{"name": "Medicare Levy", "percent": 2, "threshold": 24276, "shade_in": 10}],
+		"study_loan": [{"lower": 51550, "percent": 5}]}`), 0644)
+
+	registry, err := TaxBracket.ReadRegistry(taxFile)
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	// a store that doesn't exist yet starts empty
+	storeFile := filepath.Join(dir, "ytd.json")
+	if YTD, err = ReadYTDStore(storeFile); err != nil {
+		t.Fatalf("FAILED: ReadYTDStore() = %v", err)
+	}
+	defer func() { YTD = nil }()
+
+	// totals are kept by financial year, or failing that effective dates: a CSV tax table has neither
+	if err := YTD.CheckTables(registry); err != nil {
+		t.Errorf("FAILED: CheckTables() = %v", err)
+	}
+
+	undated, _ := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err := YTD.CheckTables(undated); err == nil {
+		t.Errorf("FAILED: CheckTables() accepted a tax table with no financial year or effective dates")
+	}
+
+	dated := &TaxBracket.TaxTable{EffectiveFrom: "2023-07-01", EffectiveTo: "2024-06-30", From: time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	if year, err := ytdYear(dated); err != nil || year != "2023-07-01/2024-06-30" {
+		t.Errorf("FAILED: ytdYear() of a table with effective dates only = %s, %v: expected <2023-07-01/2024-06-30>", year, err)
+	}
+
+	run := func(rows ...string) ([]*PayrollRecord, ValidationReport, string) {
+		var records []*PayrollRecord
+		for _, row := range rows {
+			prr, _ := createPayrollRecord(strings.Split(row, ","))
+			records = append(records, prr)
+		}
+
+		report := CheckTaxTables("input.csv", records, registry)
+		TrackConcessionalCap(records, registry)
+		if err := AccumulateYTD(records, registry); err != nil {
+			t.Fatalf("FAILED: AccumulateYTD() = %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteOutput(&buf, records, registry); err != nil {
+			t.Fatalf("FAILED: WriteOutput() = %v", err)
+		}
+
+		return records, report, buf.String()
+	}
+
+	// first run: totals accumulate across an employee's records within the run
+	records, _, got := run("Ryan,Chen,120000,10%,01 March – 31 March,,,yes", "David,Rudd,60050,9%,01 March – 31 March", "Ryan,Chen,120000,10%,01 April – 30 April,,,yes")
+	expected := "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Medicare Levy,Study Loan,YTD Gross,YTD Pre-Tax Deductions,YTD Tax,YTD Levies,YTD Study Loan,YTD Post-Tax Deductions,YTD Net,YTD Super\n" +
+		"Ryan Chen,01 March – 31 March,10000.00,2696.00,6604.00,1000.00,200.00,500.00,10000.00,0.00,2696.00,200.00,500.00,0.00,6604.00,1000.00\n" +
+		"David Rudd,01 March – 31 March,5004.00,922.00,3982.00,450.00,100.00,0.00,5004.00,0.00,922.00,100.00,0.00,0.00,3982.00,450.00\n" +
+		"Ryan Chen,01 April – 30 April,10000.00,2696.00,6604.00,1000.00,200.00,500.00,20000.00,0.00,5392.00,400.00,1000.00,0.00,13208.00,2000.00\n"
+	if got != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", got, expected)
+	}
+
+	if err := YTD.Post(records, registry); err != nil {
+		t.Fatalf("FAILED: Post() = %v", err)
+	}
+	if err := YTD.Save(); err != nil {
+		t.Fatalf("FAILED: Save() = %v", err)
+	}
+
+	// second run: totals carry on from the store, including the concessional contributions, and a pay period already posted is rejected,
+	// however the tax table's file is named
+	if YTD, err = ReadYTDStore(storeFile); err != nil {
+		t.Fatalf("FAILED: ReadYTDStore() = %v", err)
+	}
+
+	if registry, err = TaxBracket.ReadRegistry(filepath.Join(dir, ".", "..", filepath.Base(dir), "tax.json")); err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	records, report, got := run("Ryan,Chen,120000,10%,01 May – 31 May,,,yes", "David,Rudd,60050,9%,01 March – 31 March")
+	expected = "Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Medicare Levy,Study Loan,YTD Gross,YTD Pre-Tax Deductions,YTD Tax,YTD Levies,YTD Study Loan,YTD Post-Tax Deductions,YTD Net,YTD Super\n" +
+		"Ryan Chen,01 May – 31 May,10000.00,2696.00,6604.00,1000.00,200.00,500.00,30000.00,0.00,8088.00,600.00,1500.00,0.00,19812.00,3000.00\n"
+	if got != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", got, expected)
+	}
+
+	if len(report) != 1 || report[0].Field != "period" || records[1].Valid {
+		t.Errorf("FAILED: CheckTaxTables() reported %v: expected David Rudd's March pay period to be already posted", report)
+	}
+
+	if records[0].ConcessionalYTD != Money.FromDollars(2000) {
+		t.Errorf("FAILED: ConcessionalYTD = %s: expected 2000.00 carried over from the store", records[0].ConcessionalYTD)
+	}
+
+	// the totals reconcile: gross less deductions, tax, levies and study loan repayments is net
+	ytd := records[0].YTD
+	if ytd.Gross-ytd.Deductions-ytd.Tax-ytd.Levies-ytd.StudyLoan-ytd.PostTax != ytd.Net {
+		t.Errorf("FAILED: YTD totals %+v don't reconcile to net", *ytd)
+	}
+
+	// a change of tax table part way through a financial year carries the totals over to the new table
+	brackets := `"brackets": [{"lower": 0, "upper": 18200, "percent": 0}, {"lower": 18201, "percent": 19, "above": 18200}]`
+	firstHalf, secondHalf := filepath.Join(dir, "tax-h1.json"), filepath.Join(dir, "tax-h2.json")
+	os.WriteFile(firstHalf, []byte(`{"jurisdiction": "Australia", "financial_year": "2023-24", "effective_from": "2023-07-01", "effective_to": "2023-12-31", `+brackets+`}`), 0644)
+	os.WriteFile(secondHalf, []byte(`{"jurisdiction": "Australia", "financial_year": "2023-24", "effective_from": "2024-01-01", "effective_to": "2024-06-30", `+brackets+`}`), 0644)
+
+	if registry, err = TaxBracket.ReadRegistry(firstHalf, secondHalf); err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	if YTD, err = ReadYTDStore(filepath.Join(dir, "ytd-halves.json")); err != nil {
+		t.Fatalf("FAILED: ReadYTDStore() = %v", err)
+	}
+
+	records, _, _ = run("Ryan,Chen,120000,10%,01 December 2023 – 31 December 2023", "Ryan,Chen,120000,10%,01 January 2024 – 31 January 2024")
+	if records[1].YTD == nil || records[1].YTD.Gross != Money.FromDollars(20000) || records[1].YTD.Super != Money.FromDollars(2000) {
+		t.Errorf("FAILED: AccumulateYTD() across a mid-year change of tax table gave %+v: expected YTD gross 20000.00 and super 2000.00", records[1].YTD)
+	}
+
+	if err := YTD.Post(records, registry); err != nil {
+		t.Fatalf("FAILED: Post() = %v", err)
+	}
+
+	if account := YTD.Years["Australia 2023-24"][records[1].employeeKey()]; account == nil || account.Gross != Money.FromDollars(20000) || len(account.Periods) != 2 {
+		t.Errorf("FAILED: Post() across a mid-year change of tax table stored %+v: expected YTD gross 20000.00 over two pay periods", account)
+	}
+}
+
+// test employees told apart by employee ID, including two with the same name
+func TestEmployeeIDs(t *testing.T) {
+	registry, err := TaxBracket.ReadRegistry("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	os.WriteFile(input, []byte("Employee ID,First Name,Last Name,Annual Salary,Super Rate,Pay Period\n"+
+		"E1,John,Smith,60050,9%,01 March – 31 March\n"+
+		"E2,John,Smith,120000,10%,01 March – 31 March\n"+
+		"E1,John,Smith,60050,9%,01 April – 30 April\n"+
+		"E2,Jane,Doe,50000,9%,01 April – 30 April\n"+
+		"E1,John,Smith,60050,9%,01 March – 31 March\n"), 0644)
+
+	records, report, err := ReadPayrollRecords(input)
+	if err != nil {
+		t.Fatalf("FAILED: ReadPayrollRecords() = %v", err)
+	}
+
+	// an ID can't be used for two people, or for two pay periods covering the same days
+	if len(report) != 2 || report[0].Line != 5 || report[0].Field != "id" || report[1].Line != 6 || report[1].Field != "id" {
+		t.Errorf("FAILED: ReadPayrollRecords() reported %v: expected employee ID problems on lines 5 and 6", report)
+	}
+
+	deductionsFile := filepath.Join(dir, "deductions.csv")
+	os.WriteFile(deductionsFile, []byte("Employee,Type,Description,Amount,Per\n"+
+		"E2,post-tax,Union fees,25,period\n"+
+		"John Smith,post-tax,Parking,10,period\n"), 0644)
+
+	deductions, _, err := ReadDeductions(deductionsFile)
+	if err != nil {
+		t.Fatalf("FAILED: ReadDeductions() = %v", err)
+	}
+
+	// a name shared by two employees doesn't say whose deduction it is
+	report = AttachDeductions(deductionsFile, records, deductions)
+	if len(report) != 1 || report[0].Line != 3 || report[0].Field != "employee" {
+		t.Errorf("FAILED: AttachDeductions() reported %v: expected an ambiguous name on line 3", report)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteOutput(&buf, records, registry); err != nil {
+		t.Fatalf("FAILED: WriteOutput() = %v", err)
+	}
+
+	expected := "Employee ID,Name,Pay Period,Gross Income,Income Tax,Net Income,Super,Post-Tax Deductions\n" +
+		"E1,John Smith,01 March – 31 March,5004.00,922.00,4082.00,450.00,0.00\n" +
+		"E2,John Smith,01 March – 31 March,10000.00,2696.00,7279.00,1000.00,25.00\n" +
+		"E1,John Smith,01 April – 30 April,5004.00,922.00,4082.00,450.00,0.00\n"
+	if buf.String() != expected {
+		t.Errorf("FAILED: WriteOutput() wrote <%s>: expected <%s>", buf.String(), expected)
+	}
+}
@@ -0,0 +1,262 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"TaxBracket"
+	"fmt"
+)
+
+// Payslip holds the figures calculated for a valid payroll record, as written to the output file
+type Payslip struct {
+	EmployeeID  string                       `json:"employee_id,omitempty"`
+	Name        string                       `json:"name"`
+	Period      string                       `json:"period"`       // pay period as given in the input file
+	PeriodStart string                       `json:"period_start"` // first day of the pay period, as YYYY-MM-DD
+	PeriodEnd   string                       `json:"period_end"`   // last day of the pay period, as YYYY-MM-DD
+	Frequency   string                       `json:"frequency"`
+	Scale       string                       `json:"scale"` // tax scale the employee is taxed on
+	Gross       Money.Amount                 `json:"gross"`
+	Earnings    []EarningsAmount             `json:"earnings"`            // each earnings line paid on top of salary, included in Gross
+	Timesheet   []TimesheetAmount            `json:"timesheet"`           // pay for each day's hours worked by an hourly employee, included in Gross
+	Deductions  []DeductionAmount            `json:"deductions"`          // each pre-tax deduction taken out of gross income before tax
+	Sacrifice   Money.Amount                 `json:"salary_sacrifice"`    // pre-tax deductions paid into super, included in Super
+	Tax         Money.Amount                 `json:"tax"`                 // income tax, after offsets
+	Offsets     []OffsetAmount               `json:"offsets"`             // each tax offset taken off income tax
+	Levies      []LevyAmount                 `json:"levies"`              // each levy payable, on top of tax
+	StudyLoan   Money.Amount                 `json:"study_loan"`          // study loan repayment withheld, on top of tax
+	PostTax     []DeductionAmount            `json:"post_tax_deductions"` // each post-tax deduction taken out of net pay
+	Net         Money.Amount                 `json:"net"`
+	Super       Money.Amount                 `json:"super"`
+	YTD         *YTDTotals                   `json:"ytd,omitempty"` // the employee's year-to-date totals including this payslip, if a YTD store is in use
+	TaxTable    string                       `json:"tax_table"`     // jurisdiction and financial year of the tax table used
+	Bracket     *TaxBracket.IncomeTaxBracket `json:"bracket"`       // tax bracket the annual salary fell in
+}
+
+// OutputFields are the names of the output fields, in the column order WriteOutputFile writes them. The id column is only
+// written if an employee has an employee ID.
+var OutputFields = []string{"id", "name", "period", "gross", "tax", "net", "super"}
+
+// header names written for each output field unless renamed by OutputHeaders
+var outputHeaderNames = map[string]string{
+	"id":     "Employee ID",
+	"name":   "Name",
+	"period": "Pay Period",
+	"gross":  "Gross Income",
+	"tax":    "Income Tax",
+	"net":    "Net Income",
+	"super":  "Super",
+}
+
+// OutputHeader controls whether WriteOutputFile writes a header row naming the output columns
+var OutputHeader = true
+
+// OutputHeaders renames output columns in the header row, mapping output field names to header names e.g. {"gross": "Gross Pay"}
+var OutputHeaders ColumnMapping
+
+// ParseOutputColumns reads a set of output header names of the form "field=Header Name,field=Header Name" e.g. "gross=Gross Pay"
+func ParseOutputColumns(s string) (ColumnMapping, error) {
+	return parseMapping(s, OutputFields)
+}
+
+// Payslip calculates the output figures for this payroll record, taxed by the table in force for its pay period
+func (rec *PayrollRecord) Payslip(registry *TaxBracket.Registry) (*Payslip, error) {
+	table, err := rec.TaxTable(registry)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting income tax: %v", err)
+	}
+
+	scale, err := table.Scale(rec.Scale)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting income tax: %v", err)
+	}
+
+	brac, err := rec.TaxBracket(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting income tax: %v", err)
+	}
+
+	tax, err := rec.IncomeTax(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting income tax: %v", err)
+	}
+
+	offsets, err := rec.Offsets(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting income tax: %v", err)
+	}
+
+	postTax, err := rec.PostTaxDeductions(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting net income: %v", err)
+	}
+
+	net, err := rec.NetIncome(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting net income: %v", err)
+	}
+
+	super, err := rec.SuperAmount(table)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting super amount: %v", err)
+	}
+
+	return &Payslip{
+		EmployeeID:  rec.EmployeeID,
+		Name:        rec.FullName(),
+		Period:      rec.PayPeriod(),
+		PeriodStart: rec.Period.Start.Format("2006-01-02"),
+		PeriodEnd:   rec.Period.End.Format("2006-01-02"),
+		Frequency:   rec.Frequency.String(),
+		Scale:       scale.Name,
+		Gross:       rec.GrossIncome(),
+		Earnings:    rec.EarningsLines(),
+		Timesheet:   rec.TimesheetLines(),
+		Deductions:  rec.PreTaxDeductions(),
+		Sacrifice:   rec.SalarySacrifice(),
+		Tax:         tax,
+		Offsets:     offsets,
+		Levies:      rec.Levies(table),
+		StudyLoan:   rec.StudyLoanRepayment(table),
+		PostTax:     postTax,
+		Net:         net,
+		Super:       super,
+		YTD:         rec.YTD,
+		TaxTable:    table.String(),
+		Bracket:     brac,
+	}, nil
+}
+
+// lineItemNames are the names of the levies and tax offsets given their own output columns, in column order, and whether
+// there are study loan repayment, pre-tax deduction, post-tax deduction, earnings, timesheet and year-to-date columns after them,
+// and an employee ID column before the OutputFields
+type lineItemNames struct {
+	levies     []string
+	offsets    []string
+	studyLoan  bool
+	deductions bool
+	postTax    bool
+	earnings   bool
+	timesheets bool
+	ytd        bool
+	ids        bool
+}
+
+// header names of the study loan repayment, deduction, earnings and timesheet output columns
+const (
+	studyLoanHeader  = "Study Loan"
+	deductionsHeader = "Pre-Tax Deductions"
+	sacrificeHeader  = "Salary Sacrifice"
+	postTaxHeader    = "Post-Tax Deductions"
+	earningsHeader   = "Additional Earnings"
+	hoursHeader      = "Hours Worked"
+	timesheetHeader  = "Timesheet Pay"
+)
+
+// header names of the year-to-date output columns, in column order
+var ytdHeaders = []string{"YTD Gross", "YTD Pre-Tax Deductions", "YTD Tax", "YTD Levies", "YTD Study Loan", "YTD Post-Tax Deductions", "YTD Net", "YTD Super"}
+
+// outputHeader returns the header row for the output file, with a column named for each levy and then each offset after the OutputFields,
+// and study loan, deduction, earnings, timesheet and year-to-date columns if wanted
+func outputHeader(items lineItemNames) []string {
+	row := []string{}
+	for _, field := range OutputFields {
+		if field == "id" && !items.ids {
+			continue
+		}
+
+		if name, ok := OutputHeaders[field]; ok {
+			row = append(row, name)
+		} else {
+			row = append(row, outputHeaderNames[field])
+		}
+	}
+
+	row = append(row, items.levies...)
+	row = append(row, items.offsets...)
+	if items.studyLoan {
+		row = append(row, studyLoanHeader)
+	}
+	if items.deductions {
+		row = append(row, deductionsHeader, sacrificeHeader)
+	}
+	if items.postTax {
+		row = append(row, postTaxHeader)
+	}
+	if items.earnings {
+		row = append(row, earningsHeader)
+	}
+	if items.timesheets {
+		row = append(row, hoursHeader, timesheetHeader)
+	}
+	if items.ytd {
+		row = append(row, ytdHeaders...)
+	}
+
+	return row
+}
+
+// row returns the payslip's figures as an output file row, in OutputFields order followed by the named levies and offsets
+// (zero for any not in the tax table used), then the study loan repayment, deductions, earnings, timesheet hours and year-to-date totals if wanted
+func (p *Payslip) row(items lineItemNames) []string {
+	row := []string{p.Name, p.Period, p.Gross.String(), p.Tax.String(), p.Net.String(), p.Super.String()}
+	if items.ids {
+		row = append([]string{p.EmployeeID}, row...)
+	}
+
+	for _, name := range items.levies {
+		amount := Money.Amount(0)
+		for _, levy := range p.Levies {
+			if levy.Name == name {
+				amount = levy.Amount
+			}
+		}
+
+		row = append(row, amount.String())
+	}
+
+	for _, name := range items.offsets {
+		amount := Money.Amount(0)
+		for _, offset := range p.Offsets {
+			if offset.Name == name {
+				amount = offset.Amount
+			}
+		}
+
+		row = append(row, amount.String())
+	}
+
+	if items.studyLoan {
+		row = append(row, p.StudyLoan.String())
+	}
+	if items.deductions {
+		row = append(row, totalOf(p.Deductions).String(), p.Sacrifice.String())
+	}
+	if items.postTax {
+		row = append(row, totalOf(p.PostTax).String())
+	}
+	if items.earnings {
+		earnings := Money.Amount(0)
+		for _, e := range p.Earnings {
+			earnings += e.Amount
+		}
+		row = append(row, earnings.String())
+	}
+	if items.timesheets {
+		pay, hours := Money.Amount(0), Money.Quantity(0)
+		for _, line := range p.Timesheet {
+			pay += line.Amount
+			hours += line.Hours
+		}
+		row = append(row, hours.String(), pay.String())
+	}
+	if items.ytd {
+		ytd := YTDTotals{}
+		if p.YTD != nil {
+			ytd = *p.YTD
+		}
+		row = append(row, ytd.Gross.String(), ytd.Deductions.String(), ytd.Tax.String(), ytd.Levies.String(), ytd.StudyLoan.String(),
+			ytd.PostTax.String(), ytd.Net.String(), ytd.Super.String())
+	}
+
+	return row
+}
@@ -0,0 +1,115 @@
+package PayrollRecord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ProrationMethod selects how the share of a pay period actually worked is measured for starters and leavers
+type ProrationMethod int
+
+const (
+	CalendarDays ProrationMethod = iota // share of calendar days covered
+	WorkingDays                         // share of working days (Monday to Friday) covered
+)
+
+// names of pro-ration methods as accepted by ParseProrationMethod
+var prorationNames = map[ProrationMethod]string{CalendarDays: "calendar", WorkingDays: "working"}
+
+// Proration is the pro-ration method used for pay periods that cover only part of a full period. The zero value counts calendar days.
+var Proration ProrationMethod
+
+// ParseProrationMethod reads a pro-ration method name: "calendar" or "working"
+func ParseProrationMethod(s string) (ProrationMethod, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for m, name := range prorationNames {
+		if s == name {
+			return m, nil
+		}
+	}
+
+	return CalendarDays, fmt.Errorf("Unknown pro-ration method <%s>", s)
+}
+
+// String returns the name of the pro-ration method
+func (m ProrationMethod) String() string {
+	return prorationNames[m]
+}
+
+// Set parses a pro-ration method into m, allowing a ProrationMethod to be used directly as a command line flag
+func (m *ProrationMethod) Set(s string) error {
+	parsed, err := ParseProrationMethod(s)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// FullPeriod returns the whole pay period at frequency f that pay period p falls in: the calendar month, half month or quarter
+// containing its start date, or for weekly and fortnightly pay the 7 or 14 days from its start date
+func (f PayFrequency) FullPeriod(p PayPeriod) PayPeriod {
+	y, m, d := p.Start.Date()
+	full := PayPeriod{Text: p.Text}
+
+	switch f {
+	case Weekly:
+		full.Start, full.End = p.Start, p.Start.AddDate(0, 0, 6)
+	case Fortnightly:
+		full.Start, full.End = p.Start, p.Start.AddDate(0, 0, 13)
+	case SemiMonthly:
+		if d <= 15 {
+			full.Start, full.End = date(y, m, 1), date(y, m, 15)
+		} else {
+			full.Start, full.End = date(y, m, 16), date(y, m+1, 0)
+		}
+	case Quarterly:
+		q := (m-1)/3*3 + 1 // first month of the quarter
+		full.Start, full.End = date(y, q, 1), date(y, q+3, 0)
+	default:
+		full.Start, full.End = date(y, m, 1), date(y, m+1, 0)
+	}
+
+	return full
+}
+
+// date returns midnight UTC on the given day, normalising out of range months and days like time.Date
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// WorkingDays returns the number of days Monday to Friday covered by the period
+func (p PayPeriod) WorkingDays() int {
+	n := 0
+	for d := p.Start; !d.After(p.End); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			n++
+		}
+	}
+
+	return n
+}
+
+// ProrationFactor returns the share of a full pay period covered by this record's pay period, as the fraction num/den,
+// measured according to the Proration method. A record covering a full pay period returns 1/1.
+func (rec *PayrollRecord) ProrationFactor() (num, den int64) {
+	if rec.Period.Start.IsZero() {
+		return 1, 1 // no pay period dates to pro-rate by
+	}
+
+	full := rec.Frequency.FullPeriod(rec.Period)
+
+	if Proration == WorkingDays {
+		num, den = int64(rec.Period.WorkingDays()), int64(full.WorkingDays())
+	} else {
+		num, den = int64(rec.Period.Days()), int64(full.Days())
+	}
+
+	if den == 0 || num >= den {
+		return 1, 1
+	}
+
+	return num, den
+}
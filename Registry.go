@@ -0,0 +1,129 @@
+package TaxBracket
+
+import (
+	"fmt"
+	"time"
+)
+
+// Registry is a set of tax tables in force over different, non-overlapping date ranges, e.g. one per financial year
+type Registry struct {
+	Tables []*TaxTable
+}
+
+// NewRegistry collects a set of tax tables into a registry, checking that no two are in force on the same date.
+// A table without effective dates is in force on every date, so can only be used on its own.
+func NewRegistry(tables ...*TaxTable) (*Registry, error) {
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("No tax tables given")
+	}
+
+	for i, a := range tables {
+		for _, b := range tables[i+1:] {
+			if a.overlaps(b) {
+				return nil, fmt.Errorf("Tax tables <%s> and <%s> have overlapping effective dates", a.Describe(), b.Describe())
+			}
+		}
+	}
+
+	return &Registry{Tables: tables}, nil
+}
+
+// ReadRegistry reads a set of tax table configuration files (see ReadTaxTable) into a registry
+func ReadRegistry(inputFiles ...string) (*Registry, error) {
+	tables := []*TaxTable{}
+	for _, file := range inputFiles {
+		table, err := ReadTaxTable(file)
+		if err != nil {
+			return nil, err
+		}
+
+		tables = append(tables, table)
+	}
+
+	return NewRegistry(tables...)
+}
+
+// TableFor returns the tax table in force on a date
+func (reg *Registry) TableFor(date time.Time) (*TaxTable, error) {
+	for _, table := range reg.Tables {
+		if table.InForce(date) {
+			return table, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No tax table is in force on %s", date.Format("2 January 2006"))
+}
+
+// LevyNames returns the names of the levies defined across all tables in the registry, in the order first defined
+func (reg *Registry) LevyNames() []string {
+	return reg.names(func(table *TaxTable) (names []string) {
+		for _, levy := range table.Levies {
+			names = append(names, levy.Name)
+		}
+		return names
+	})
+}
+
+// OffsetNames returns the names of the offsets defined across all tables in the registry, in the order first defined
+func (reg *Registry) OffsetNames() []string {
+	return reg.names(func(table *TaxTable) (names []string) {
+		for _, offset := range table.Offsets {
+			names = append(names, offset.Name)
+		}
+		return names
+	})
+}
+
+// HasStudyLoan reports whether any table in the registry has study loan repayment bands
+func (reg *Registry) HasStudyLoan() bool {
+	for _, table := range reg.Tables {
+		if len(table.StudyLoan) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// names collects the distinct names listed by tableNames across all tables in the registry, in the order first listed
+func (reg *Registry) names(tableNames func(*TaxTable) []string) []string {
+	names := []string{}
+	seen := map[string]bool{}
+
+	for _, table := range reg.Tables {
+		for _, name := range tableNames(table) {
+			if !seen[name] {
+				names = append(names, name)
+				seen[name] = true
+			}
+		}
+	}
+
+	return names
+}
+
+// InForce reports whether the table applies on a date. A table without a from or to date is open-ended on that side.
+func (table *TaxTable) InForce(date time.Time) bool {
+	return (table.From.IsZero() || !date.Before(table.From)) && (table.To.IsZero() || !date.After(table.To))
+}
+
+// overlaps reports whether two tables are in force on any of the same dates
+func (table *TaxTable) overlaps(other *TaxTable) bool {
+	startsBeforeOtherEnds := table.From.IsZero() || other.To.IsZero() || !table.From.After(other.To)
+	otherStartsBeforeEnds := other.From.IsZero() || table.To.IsZero() || !other.From.After(table.To)
+	return startsBeforeOtherEnds && otherStartsBeforeEnds
+}
+
+// Describe names a table for messages, falling back to its effective dates if it has no jurisdiction or financial year,
+// or the file it was read from if it has neither
+func (table *TaxTable) Describe() string {
+	if name := table.String(); name != "" {
+		return name
+	}
+
+	if table.EffectiveFrom == "" && table.EffectiveTo == "" && table.File != "" {
+		return table.File
+	}
+
+	return fmt.Sprintf("%s to %s", table.EffectiveFrom, table.EffectiveTo)
+}
@@ -0,0 +1,92 @@
+package PayrollRecord
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RejectsFileName returns the name of the rejects file written alongside the output for an input file e.g. input.csv -> input-rejects.csv
+func RejectsFileName(inFileName string) string {
+	return outputBaseName(inFileName) + "-rejects.csv"
+}
+
+// WriteRejectsFile writes the invalid records among records to a CSV file so they can be corrected and re-submitted.
+// Each row holds the input line number and the reasons the record was rejected, followed by the record's row as given in the input file,
+// under a header row naming the input columns (see rejectsHeader), so the file can be read back in once corrected.
+// It returns the number of records written. If there are no invalid records no file is written, and a rejects file left by
+// an earlier run is removed so it isn't mistaken for this run's. See writeFile for how existing files are treated.
+func WriteRejectsFile(fileName string, records []*PayrollRecord) (int, error) {
+	rejects := []*PayrollRecord{}
+	for _, rec := range records {
+		if !rec.Valid {
+			rejects = append(rejects, rec)
+		}
+	}
+
+	if len(rejects) == 0 {
+		return 0, removeFile(fileName)
+	}
+
+	return len(rejects), writeFile(fileName, func(w io.Writer) error {
+		return writeRejects(w, rejects)
+	})
+}
+
+// rejectsHeader returns the header row of a rejects file: line and reasons columns, followed by the input file's header row. An input
+// file with no header row has its columns named by input field (see InputFields), which are read back by header name just the same.
+func rejectsHeader(rejects []*PayrollRecord) []string {
+	header := []string{"Line", "Reasons"}
+	if rejects[0].Header != nil {
+		return append(header, rejects[0].Header...)
+	}
+
+	columns := 0
+	for _, rec := range rejects {
+		if len(rec.Row) > columns {
+			columns = len(rec.Row)
+		}
+	}
+
+	for i := 0; i < columns; i++ {
+		name := ""
+		if i < len(InputFields) {
+			name = InputFields[i]
+		}
+		header = append(header, name)
+	}
+
+	return header
+}
+
+// writeRejects writes rejected records as CSV rows of line number, reasons and input row, under a header row (see rejectsHeader)
+func writeRejects(w io.Writer, rejects []*PayrollRecord) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = Delimiter
+
+	if err := csvWriter.Write(rejectsHeader(rejects)); err != nil {
+		return fmt.Errorf("Error writing rejects file: %v", err)
+	}
+
+	for _, rec := range rejects {
+		// collect the reasons for rejection without the file and line, which have their own column
+		reasons := []string{}
+		for _, verr := range rec.Errors {
+			reasons = append(reasons, (&ValidationError{Field: verr.Field, Value: verr.Value, Reason: verr.Reason}).Error())
+		}
+
+		if len(reasons) == 0 {
+			reasons = append(reasons, rec.ErrorStr)
+		}
+
+		row := append([]string{strconv.Itoa(rec.Line), strings.Join(reasons, "; ")}, rec.Row...)
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("Error writing rejects file: %v", err)
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
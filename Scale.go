@@ -0,0 +1,91 @@
+package TaxBracket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultScale is the name of the tax scale given by a table's top-level brackets, used for employees with no scale of their own
+const DefaultScale = "resident"
+
+// Scale is a named set of tax brackets within a tax table for a class of employee, such as those not claiming the tax-free
+// threshold, non-residents or working holiday makers. Levies and offsets in the table apply on every scale unless exempted.
+type Scale struct {
+	Name     string              `json:"name" yaml:"name"` // name of the scale as given in the input file e.g. "non-resident"
+	Brackets []*IncomeTaxBracket `json:"brackets" yaml:"brackets"`
+	Exempt   []string            `json:"exempt" yaml:"exempt"` // names of the table's levies and offsets that do not apply on this scale
+}
+
+// Scale returns the named tax scale of the table, compared case-insensitively. The default scale ("" or DefaultScale) has
+// the table's top-level brackets.
+func (table *TaxTable) Scale(name string) (*Scale, error) {
+	if name == "" || strings.EqualFold(name, DefaultScale) {
+		return &Scale{Name: DefaultScale, Brackets: table.Brackets}, nil
+	}
+
+	for _, scale := range table.Scales {
+		if strings.EqualFold(scale.Name, name) {
+			return scale, nil
+		}
+	}
+
+	return nil, fmt.Errorf("No tax scale <%s> in tax table <%s>", name, table.Describe())
+}
+
+// Applies reports whether the levy or offset with the given name applies on this scale
+func (scale *Scale) Applies(name string) bool {
+	for _, exempt := range scale.Exempt {
+		if exempt == name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkScales applies sanity checks to a table's named scales: each must have a unique name, valid brackets and
+// exempt only levies and offsets the table defines
+func (table *TaxTable) checkScales() error {
+	names := map[string]bool{DefaultScale: true}
+
+	for i, scale := range table.Scales {
+		if scale == nil || scale.Name == "" {
+			return fmt.Errorf("Tax scale %d has no name", i+1)
+		}
+
+		name := strings.ToLower(scale.Name)
+		if names[name] {
+			return fmt.Errorf("Tax scale <%s> is defined more than once", scale.Name)
+		}
+		names[name] = true
+
+		if err := checkBrackets(scale.Brackets); err != nil {
+			return fmt.Errorf("%v on tax scale <%s>", err, scale.Name)
+		}
+
+		for _, exempt := range scale.Exempt {
+			if !table.hasLineItem(exempt) {
+				return fmt.Errorf("Tax scale <%s> is exempt from <%s>, which is not a levy or offset in the table", scale.Name, exempt)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasLineItem reports whether the table defines a levy or offset with the given name
+func (table *TaxTable) hasLineItem(name string) bool {
+	for _, levy := range table.Levies {
+		if levy.Name == name {
+			return true
+		}
+	}
+
+	for _, offset := range table.Offsets {
+		if offset.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
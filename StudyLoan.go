@@ -0,0 +1,64 @@
+package TaxBracket
+
+import (
+	"Money"
+	"fmt"
+)
+
+// RepaymentBand is a range of income over which study loan (HELP) repayments are a percentage of the whole income.
+// Unlike tax brackets the percentage is not marginal: it applies to every dollar of income once the band is reached.
+type RepaymentBand struct {
+	Lower   Money.Amount `json:"lower" yaml:"lower"`     // lower income limit of the band
+	Upper   Money.Amount `json:"upper" yaml:"upper"`     // upper income limit of the band (zero for the topmost band)
+	Percent Money.Rate   `json:"percent" yaml:"percent"` // percentage of the whole income repaid
+}
+
+// AnnualRepayment calculates the study loan repayment on an annual income from the table's repayment bands.
+// Incomes below the first band (the repayment threshold) repay nothing. As with tax brackets (see FindBracket) a band runs
+// up to the next band's lower limit, so incomes in cents between whole dollar limits fall in the band below.
+func (table *TaxTable) AnnualRepayment(income Money.Amount) Money.Exact {
+	for i, band := range table.StudyLoan {
+		if income < band.Lower {
+			continue
+		}
+
+		if i < len(table.StudyLoan)-1 {
+			if income < table.StudyLoan[i+1].Lower {
+				return income.Exact().MulRate(band.Percent)
+			}
+		} else if band.Upper == 0 || income <= band.Upper {
+			return income.Exact().MulRate(band.Percent)
+		}
+	}
+
+	return Money.Amount(0).Exact()
+}
+
+// checkRepaymentBands applies sanity checks to a table's study loan repayment bands: they must run upwards without
+// overlapping, and only the last may be open-ended
+func checkRepaymentBands(bands []*RepaymentBand) error {
+	prevUpper := Money.Amount(-1)
+
+	for i, band := range bands {
+		if band == nil {
+			return fmt.Errorf("Empty study loan repayment band %d", i+1)
+		}
+
+		top := band.Upper == 0
+		if top && i != len(bands)-1 {
+			return fmt.Errorf("Only the last study loan repayment band may have no upper limit (band %d)", i+1)
+		}
+
+		if (!top && band.Lower >= band.Upper) || band.Lower <= prevUpper {
+			return fmt.Errorf("Study loan repayment band %d overlaps the band before it or has lower limit >= upper limit", i+1)
+		}
+
+		if band.Percent < 0 || band.Percent > 100*Money.RateScale {
+			return fmt.Errorf("Study loan repayment band %d percentage must be between 0%% and 100%%", i+1)
+		}
+
+		prevUpper = band.Upper
+	}
+
+	return nil
+}
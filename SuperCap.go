@@ -0,0 +1,108 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"TaxBracket"
+	"fmt"
+	"strings"
+)
+
+// CapPolicy selects what happens when an employee's super would take their concessional contributions for the year over the cap
+type CapPolicy int
+
+const (
+	WarnOverCap CapPolicy = iota // pay the super in full and warn
+	LimitToCap                   // pay only as much employer super as the cap leaves room for, and warn
+)
+
+// names of cap policies as accepted by ParseCapPolicy
+var capPolicyNames = map[CapPolicy]string{WarnOverCap: "warn", LimitToCap: "cap"}
+
+// SuperCap is the policy applied to the concessional contributions cap (see TaxBracket.SuperLimits). The zero value warns.
+var SuperCap CapPolicy
+
+// ParseCapPolicy reads a cap policy name: "warn" or "cap"
+func ParseCapPolicy(s string) (CapPolicy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for p, name := range capPolicyNames {
+		if s == name {
+			return p, nil
+		}
+	}
+
+	return WarnOverCap, fmt.Errorf("Unknown super cap policy <%s>", s)
+}
+
+// String returns the name of the cap policy
+func (p CapPolicy) String() string {
+	return capPolicyNames[p]
+}
+
+// Set parses a cap policy into p, allowing a CapPolicy to be used directly as a command line flag
+func (p *CapPolicy) Set(s string) error {
+	parsed, err := ParseCapPolicy(s)
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+	return nil
+}
+
+// TrackConcessionalCap works through the valid records in order, setting each one's ConcessionalYTD to the super paid to the same
+// employee by earlier records in the same financial year (see yearKey), and by earlier runs if there is a YTD
+// store, so SuperAmount can apply the concessional cap. It returns a warning for each record taking an employee over the cap.
+func TrackConcessionalCap(records []*PayrollRecord, registry *TaxBracket.Registry) []string {
+	warnings := []string{}
+	ytd := map[string]map[string]Money.Amount{}
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		table, err := rec.TaxTable(registry)
+		if err != nil {
+			continue // left for CheckTaxTables to report
+		}
+
+		year := yearKey(table)
+		if ytd[year] == nil {
+			ytd[year] = map[string]Money.Amount{}
+		}
+
+		key := rec.employeeKey()
+		if _, ok := ytd[year][key]; !ok {
+			if account := YTD.account(table, key); account != nil {
+				ytd[year][key] = account.Super
+			}
+		}
+		rec.ConcessionalYTD = ytd[year][key]
+
+		full, err := rec.uncappedSuper(table)
+		if err != nil {
+			continue // left for SuperAmount to report
+		}
+
+		super, _ := rec.SuperAmount(table)
+		ytd[year][key] += super
+
+		limit := table.Super.ConcessionalCap
+		if limit == 0 || rec.ConcessionalYTD+full <= limit {
+			continue
+		}
+
+		// salary sacrifice is paid in full even under LimitToCap, so can still take contributions over the cap
+		where := fmt.Sprintf("%s line %d", rec.FullName(), rec.Line)
+		if super < full {
+			warnings = append(warnings, fmt.Sprintf("%s: super limited to %s (from %s) by the %s concessional contributions cap for %s",
+				where, super, full, limit, table.Describe()))
+		}
+		if rec.ConcessionalYTD+super > limit {
+			warnings = append(warnings, fmt.Sprintf("%s: super of %s takes concessional contributions to %s, over the %s cap for %s",
+				where, super, rec.ConcessionalYTD+super, limit, table.Describe()))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,21 @@
+package TaxBracket
+
+import (
+	"Money"
+	"fmt"
+)
+
+// SuperLimits are the limits on super contributions for a financial year. A zero limit is not applied.
+type SuperLimits struct {
+	MaxContributionBase Money.Amount `json:"max_contribution_base" yaml:"max_contribution_base"` // quarterly earnings above which no super guarantee is due
+	ConcessionalCap     Money.Amount `json:"concessional_cap" yaml:"concessional_cap"`           // annual limit on concessional (before-tax) contributions
+}
+
+// check applies sanity checks to the super limits
+func (limits SuperLimits) check() error {
+	if limits.MaxContributionBase < 0 || limits.ConcessionalCap < 0 {
+		return fmt.Errorf("Super limits must not be negative")
+	}
+
+	return nil
+}
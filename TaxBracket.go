@@ -2,26 +2,56 @@
 package TaxBracket
 
 import (
+	"Money" // custom package providing fixed-point monetary values
 	"encoding/csv"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
 	"strings"
 )
 
 // Type struct representing an income tax bracket
 type IncomeTaxBracket struct {
-	Lower   float64 // lower salary limit of tax bracket
-	Upper   float64 // upper salary limit of tax bracket
-	Percent float64 // percentage tax to be levied above a certain threshold
-	Lump    float64 // lump sum to be paid for this bracket, if any
-	Above   float64 // threshold above which percentage tax has to be paid
+	Lower   Money.Amount `json:"lower" yaml:"lower"`     // lower salary limit of tax bracket
+	Upper   Money.Amount `json:"upper" yaml:"upper"`     // upper salary limit of tax bracket (zero for the topmost bracket)
+	Percent Money.Rate   `json:"percent" yaml:"percent"` // percentage tax to be levied above a certain threshold
+	Lump    Money.Amount `json:"lump" yaml:"lump"`       // lump sum to be paid for this bracket, if any
+	Above   Money.Amount `json:"above" yaml:"above"`     // threshold above which percentage tax has to be paid
 }
 
 // utility method to print out a tax bracket - mostly used for debug purposes
 func (itb *IncomeTaxBracket) Print() {
-	fmt.Printf("[Lower: $%.2f, Upper: $%.2f, Percent: %.2f%%, Lump Sum: $%.2f, Above: $%.2f]\n", itb.Lower, itb.Upper, itb.Percent, itb.Lump, itb.Above)
+	fmt.Printf("[Lower: $%s, Upper: $%s, Percent: %s, Lump Sum: $%s, Above: $%s]\n", itb.Lower, itb.Upper, itb.Percent, itb.Lump, itb.Above)
+}
+
+// FindBracket returns the tax bracket that an annual income falls in. A bracket runs up to the next bracket's lower limit, so
+// incomes in cents between whole dollar limits e.g. 37000.50 between 37000 and 37001 fall in the bracket below; only the last
+// bracket's upper limit (if it has one) is an inclusive cap.
+func FindBracket(brackets []*IncomeTaxBracket, income Money.Amount) (*IncomeTaxBracket, error) {
+	// for each tax bracket configured
+	for i, brac := range brackets {
+		if income < brac.Lower {
+			continue
+		}
+
+		if i < len(brackets)-1 {
+			// if not the last tax bracket, check if the income is below the next bracket's lower limit
+			if income < brackets[i+1].Lower {
+				return brac, nil
+			}
+		} else if brac.Upper == 0 || income <= brac.Upper {
+			// upper limit would be automatically set to zero for topmost tax bracket
+			return brac, nil
+		}
+	}
+
+	// no fitting bracket found for this income - return error
+	return nil, fmt.Errorf("No fitting tax bracket was found for salary amount %s", income)
+}
+
+// AnnualTax calculates the annual tax payable in this bracket on an annual income: any lump sum plus the bracket's percentage of the income above its threshold
+func (itb *IncomeTaxBracket) AnnualTax(income Money.Amount) Money.Exact {
+	return (income - itb.Above).Exact().MulRate(itb.Percent).Add(itb.Lump.Exact())
 }
 
 // ReadTaxBracketsConfig takes in a config file name and reads in a set of tax bracket configurations
@@ -39,8 +69,8 @@ func ReadTaxBracketsConfig(inputFile string) ([]*IncomeTaxBracket, error) {
 	csvReader := csv.NewReader(fileHandle) // initialize CSV reader
 	brackets := []*IncomeTaxBracket{}      // initialize empty slice ofIncomeTaxBracket struct references to store read-in brackets
 
-	i := 0            // counter to keep track of number or rows read
-	prev_upper := 0.0 // placecholder to keep track of the previously-read bracket's upper income limit
+	i := 0                        // counter to keep track of number or rows read
+	prev_upper := Money.Amount(0) // placecholder to keep track of the previously-read bracket's upper income limit
 
 	// for each row in tx brackets config file
 	for {
@@ -59,49 +89,38 @@ func ReadTaxBracketsConfig(inputFile string) ([]*IncomeTaxBracket, error) {
 		}
 
 		// read in first field of row: lower limit of income for this tax bracket
-		lower, err_low := strconv.ParseFloat(strings.TrimSpace(row[0]), 64)
+		lower, err_low := Money.Parse(row[0])
 
 		top := false                     // flag indicating topmost tax bracket
 		upp := strings.TrimSpace(row[1]) // tentatively read in first field of row: lower limimt of income for this tax bracket
-		upper := 0.0                     // placeholder for actual upper income limit
+		upper := Money.Amount(0)         // placeholder for actual upper income limit
 		var err_upp error                // placeholder for any read error
 		if upp != "" {
 			// if value defined for second field, read it in: upper taxable income limit for this bracket
-			upper, err_upp = strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+			upper, err_upp = Money.Parse(row[1])
 		} else {
 			// upper field could be empty if this is the topmost bracket (e.g. 180,000 and up)
 			// set flag indicating uppermost bracket
 			top = true
 		}
 
-		percent, err_perc := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)  // read in tax percentage value: third field of row
-		lump, err_lump := strconv.ParseFloat(strings.TrimSpace(row[3]), 64)     // read in lump sum value: fourth field of row
-		threshold, err_thr := strconv.ParseFloat(strings.TrimSpace(row[4]), 64) // read in tax percentage value: fifth field of row
+		percent, err_perc := Money.ParseRate(row[2]) // read in tax percentage value: third field of row
+		lump, err_lump := Money.Parse(row[3])        // read in lump sum value: fourth field of row
+		threshold, err_thr := Money.Parse(row[4])    // read in tax percentage value: fifth field of row
 
 		// ensure all values were read in without error
 		if err_low != nil || err_upp != nil || err_perc != nil || err_lump != nil || err_thr != nil {
 			return nil, fmt.Errorf("readTaxBrackets(): Error reading tax bracket config record: <%s>\n", row)
 		}
 
-		// sanity check - upper limit cannot be smaller than lower limit
-		if lower >= upper && !top {
-			return nil, fmt.Errorf("readTaxBrackets(): Lower limit >= upper limit in input <%s>\n", row)
-		}
+		newTaxBracket := &IncomeTaxBracket{lower, upper, percent, lump, threshold}
 
-		// if this is the first row
-		if i == 0 {
-			// first bracket lower limit must be zero
-			if lower != 0 {
-				return nil, fmt.Errorf("readTaxBrackets(): First bracket lower limit != 0 in input <%s>\n", row)
-			}
-		} else {
-			if lower <= prev_upper {
-				return nil, fmt.Errorf("readTaxBrackets(): Current bracket's lower limit <= previous upper limit in input <%s>\n", row)
-			}
+		// sanity check bracket limits against each other and the previous bracket
+		if err := checkBracket(newTaxBracket, top, i, prev_upper); err != nil {
+			return nil, fmt.Errorf("readTaxBrackets(): %v in input <%s>\n", err, row)
 		}
 
 		prev_upper = upper
-		newTaxBracket := &IncomeTaxBracket{lower, upper, percent, lump, threshold}
 		brackets = append(brackets, newTaxBracket)
 		i++
 
@@ -116,3 +135,25 @@ func ReadTaxBracketsConfig(inputFile string) ([]*IncomeTaxBracket, error) {
 
 	return brackets, nil
 }
+
+// checkBracket applies the sanity checks to the i'th bracket (counting from zero) of a set, given the previous bracket's upper limit
+func checkBracket(brac *IncomeTaxBracket, top bool, i int, prevUpper Money.Amount) error {
+	// sanity check - upper limit cannot be smaller than lower limit
+	if brac.Lower >= brac.Upper && !top {
+		return fmt.Errorf("Lower limit >= upper limit")
+	}
+
+	// if this is the first row
+	if i == 0 {
+		// first bracket lower limit must be zero
+		if brac.Lower != 0 {
+			return fmt.Errorf("First bracket lower limit != 0")
+		}
+	} else {
+		if brac.Lower <= prevUpper {
+			return fmt.Errorf("Current bracket's lower limit <= previous upper limit")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// Go tests are placed in files with the pattern *_test.go. Test Methods have the signature func <TestMethod>(t *testing.T) The tests are run with "go test" command.
+
+package TaxBracket
+
+import (
+	"Money"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// test Print()
+func TestWritePrint_TaxBracket(t *testing.T) {
+
+}
+
+// test Print()
+func TestReadTaxBracketsConfig(t *testing.T) {
+
+}
+
+// test FindBracket() with incomes in cents between whole dollar bracket limits
+func TestFindBracket(t *testing.T) {
+	brackets, err := ReadTaxBracketsConfig("TAX_CONFIG.csv")
+	if err != nil {
+		t.Fatalf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	for income, expected := range map[string]int{"0": 0, "18200": 0, "18200.99": 0, "18201": 1, "37000.44": 1, "37000.50": 1, "37001": 2, "180000.01": 3, "180001": 4, "1000000": 4} {
+		amount, _ := Money.Parse(income)
+		if brac, err := FindBracket(brackets, amount); err != nil || brac != brackets[expected] {
+			t.Errorf("FAILED: FindBracket(%s) = %v, %v: expected bracket %d", income, brac, err, expected)
+		}
+	}
+
+	// only the last bracket's upper limit caps the incomes found
+	capped := []*IncomeTaxBracket{{Lower: 0, Upper: Money.FromDollars(100)}, {Lower: Money.FromDollars(101), Upper: Money.FromDollars(200)}}
+	if _, err := FindBracket(capped, Money.FromDollars(201)); err == nil {
+		t.Errorf("FAILED: FindBracket() above the last bracket's upper limit should return an error")
+	}
+}
+
+// test ReadTaxTable() with JSON and YAML configuration files
+func TestReadTaxTable(t *testing.T) {
+	csvBrackets, err := ReadTaxBracketsConfig("TAX_CONFIG.csv")
+	if err != nil {
+		t.Errorf("FAILED: error loading tax brackets config: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"tax.json": `{"jurisdiction": "Australia", "financial_year": "2012-13", "effective_from": "2012-07-01", "effective_to": "2013-06-30",
+			"brackets": [
+				{"lower": 0, "upper": 18200, "percent": 0, "lump": 0, "above": 0},
+				{"lower": 18201, "upper": 37000, "percent": 19, "lump": 0, "above": 18200},
+				{"lower": 37001, "upper": 80000, "percent": 32.5, "lump": 3572, "above": 37000},
+				{"lower": 80001, "upper": 180000, "percent": 37, "lump": 17547, "above": 80000},
+				{"lower": 180001, "percent": 45, "lump": 54547, "above": 180000}]}`,
+		"null.json": `{"jurisdiction": "Australia", "financial_year": "2012-13", "effective_from": "2012-07-01", "effective_to": "2013-06-30",
+			"brackets": [
+				{"lower": 0, "upper": 18200, "percent": 0, "lump": null, "above": 0},
+				{"lower": 18201, "upper": 37000, "percent": 19, "lump": 0, "above": 18200},
+				{"lower": 37001, "upper": 80000, "percent": 32.5, "lump": 3572, "above": 37000},
+				{"lower": 80001, "upper": 180000, "percent": 37, "lump": 17547, "above": 80000},
+				{"lower": 180001, "upper": null, "percent": 45, "lump": 54547, "above": 180000}]}`,
+		"tax.yaml": "jurisdiction: Australia\nfinancial_year: 2012-13\neffective_from: 2012-07-01\neffective_to: 2013-06-30\nbrackets:\n" +
+			"  - {lower: 0, upper: 18200, percent: 0, lump: 0, above: 0}\n" +
+			"  - {lower: 18201, upper: 37000, percent: 19, lump: 0, above: 18200}\n" +
+			"  - {lower: 37001, upper: 80000, percent: 32.5, lump: 3572, above: 37000}\n" +
+			"  - {lower: 80001, upper: 180000, percent: 37, lump: 17547, above: 80000}\n" +
+			"  - {lower: 180001, percent: 45, lump: 54547, above: 180000}\n",
+	}
+
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte(content), 0644)
+
+		table, err := ReadTaxTable(file)
+		if err != nil {
+			t.Errorf("FAILED: ReadTaxTable(%s) = %v", name, err)
+			continue
+		}
+
+		if table.String() != "Australia 2012-13" || table.From.Format("2006-01-02") != "2012-07-01" || table.To.Format("2006-01-02") != "2013-06-30" {
+			t.Errorf("FAILED: ReadTaxTable(%s) read table <%s> effective %v - %v", name, table, table.From, table.To)
+		}
+
+		if len(table.Brackets) != len(csvBrackets) {
+			t.Errorf("FAILED: ReadTaxTable(%s) read %d brackets: expected %d", name, len(table.Brackets), len(csvBrackets))
+			continue
+		}
+
+		for i, brac := range table.Brackets {
+			if *brac != *csvBrackets[i] {
+				t.Errorf("FAILED: ReadTaxTable(%s) bracket %d = %+v: expected <%+v>", name, i, *brac, *csvBrackets[i])
+			}
+		}
+	}
+
+	// invalid tables fail the same checks as the CSV loader
+	invalid := map[string]string{
+		"nonzero.json":  `{"brackets": [{"lower": 1, "upper": 100, "percent": 0}]}`,
+		"overlap.json":  `{"brackets": [{"lower": 0, "upper": 100, "percent": 0}, {"lower": 50, "percent": 10, "above": 100}]}`,
+		"open.yaml":     "brackets:\n  - {lower: 0, percent: 0}\n  - {lower: 100, percent: 10}\n",
+		"empty.yaml":    "jurisdiction: Nowhere\n",
+		"dates.json":    `{"effective_from": "2013-07-01", "effective_to": "2013-06-30", "brackets": [{"lower": 0, "percent": 0}]}`,
+		"badvalue.yaml": "brackets:\n  - {lower: 0, percent: lots}\n",
+		"misspelt.json": `{"brackets": [{"lower": 0, "upper": 18200, "percnt": 0}, {"lower": 18201, "percent": 19, "above": 18200}]}`,
+		"misspelt.yaml": "brackets:\n  - {lower: 0, uper: 18200, percent: 0}\n",
+	}
+
+	for name, content := range invalid {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte(content), 0644)
+
+		if _, err := ReadTaxTable(file); err == nil {
+			t.Errorf("FAILED: ReadTaxTable(%s) should return an error", name)
+		}
+	}
+}
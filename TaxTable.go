@@ -0,0 +1,186 @@
+package TaxBracket
+
+import (
+	"Money"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3" // YAML parser: go get gopkg.in/yaml.v3
+)
+
+// TaxTable is a named set of income tax brackets together with the jurisdiction and dates they apply to.
+// It is read from a self-describing JSON or YAML configuration file, for example (YAML):
+//
+//	jurisdiction: Australia
+//	financial_year: 2023-24
+//	effective_from: 2023-07-01
+//	effective_to: 2024-06-30
+//	brackets:
+//	  - {lower: 0, upper: 18200, percent: 0, lump: 0, above: 0}
+//	  - {lower: 18201, upper: 45000, percent: 19, lump: 0, above: 18200}
+//	  - {lower: 45001, percent: 32.5, lump: 5092, above: 45000}
+//	levies:
+//	  - {name: Medicare Levy, percent: 2, threshold: 24276, shade_in: 10}
+//	offsets:
+//	  - name: LITO
+//	    amount: 700
+//	    phase_out:
+//	      - {from: 37500, to: 45000, percent: 5}
+//	      - {from: 45000, to: 66667, percent: 1.5}
+//	scales:
+//	  - name: non-resident
+//	    exempt: [Medicare Levy, LITO]
+//	    brackets:
+//	      - {lower: 0, upper: 120000, percent: 32.5, lump: 0, above: 0}
+//	      - {lower: 120001, percent: 37, lump: 39000, above: 120000}
+//	study_loan:
+//	  - {lower: 51550, upper: 59518, percent: 1}
+//	  - {lower: 59519, upper: 63089, percent: 2}
+//	  - {lower: 63090, percent: 2.5}
+//	super: {max_contribution_base: 62270, concessional_cap: 27500}
+//
+// The top-level brackets are the default scale (see DefaultScale); further named scales are optional (see Scale). The last
+// bracket of a scale may leave out its upper limit (or give it as zero) to cover all higher incomes. Levies and offsets are
+// optional (see Levy and Offset), as are study loan repayment bands (see RepaymentBand) and super limits (see SuperLimits).
+type TaxTable struct {
+	Jurisdiction  string              `json:"jurisdiction" yaml:"jurisdiction"`
+	FinancialYear string              `json:"financial_year" yaml:"financial_year"` // e.g. "2023-24"
+	EffectiveFrom string              `json:"effective_from" yaml:"effective_from"` // first day the brackets apply, as YYYY-MM-DD (optional)
+	EffectiveTo   string              `json:"effective_to" yaml:"effective_to"`     // last day the brackets apply, as YYYY-MM-DD (optional)
+	Brackets      []*IncomeTaxBracket `json:"brackets" yaml:"brackets"`
+	Levies        []*Levy             `json:"levies" yaml:"levies"`         // levies charged on top of bracket tax
+	Offsets       []*Offset           `json:"offsets" yaml:"offsets"`       // offsets reducing bracket tax, applied in order
+	Scales        []*Scale            `json:"scales" yaml:"scales"`         // tax scales other than the default one given by Brackets
+	StudyLoan     []*RepaymentBand    `json:"study_loan" yaml:"study_loan"` // study loan (HELP) repayment bands, from the repayment threshold up
+	Super         SuperLimits         `json:"super" yaml:"super"`           // limits on super contributions
+
+	From time.Time `json:"-" yaml:"-"` // parsed EffectiveFrom, zero if not given
+	To   time.Time `json:"-" yaml:"-"` // parsed EffectiveTo, zero if not given
+	File string    `json:"-" yaml:"-"` // configuration file the table was read from
+}
+
+// ReadTaxTable reads a tax table configuration file, choosing the format by file extension:
+// .json for JSON, .yaml or .yml for YAML, and anything else for the positional CSV format read by ReadTaxBracketsConfig
+// (which carries no jurisdiction or dates).
+func ReadTaxTable(inputFile string) (*TaxTable, error) {
+	ext := strings.ToLower(filepath.Ext(inputFile))
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		brackets, err := ReadTaxBracketsConfig(inputFile)
+		if err != nil {
+			return nil, err
+		}
+
+		return &TaxTable{Brackets: brackets, File: inputFile}, nil
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// unknown keys are refused rather than ignored, so a misspelt one can't silently leave a figure unset
+	table := &TaxTable{File: inputFile}
+	if ext == ".json" {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(table)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err = dec.Decode(table); err == io.EOF {
+			err = nil // an empty file is left to validate to report
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("readTaxTable(): Error reading tax table config <%s>: %v", inputFile, err)
+	}
+
+	if err := table.validate(); err != nil {
+		return nil, fmt.Errorf("readTaxTable(): %v in <%s>", err, inputFile)
+	}
+
+	return table, nil
+}
+
+// validate parses the table's effective dates and applies sanity checks to its brackets, levies, offsets, scales,
+// study loan repayment bands and super limits
+func (table *TaxTable) validate() error {
+	var err error
+
+	if table.EffectiveFrom != "" {
+		if table.From, err = time.Parse("2006-01-02", table.EffectiveFrom); err != nil {
+			return fmt.Errorf("Invalid effective_from date <%s>", table.EffectiveFrom)
+		}
+	}
+
+	if table.EffectiveTo != "" {
+		if table.To, err = time.Parse("2006-01-02", table.EffectiveTo); err != nil {
+			return fmt.Errorf("Invalid effective_to date <%s>", table.EffectiveTo)
+		}
+	}
+
+	if !table.From.IsZero() && !table.To.IsZero() && table.To.Before(table.From) {
+		return fmt.Errorf("effective_to date <%s> is before effective_from date <%s>", table.EffectiveTo, table.EffectiveFrom)
+	}
+
+	if err := checkBrackets(table.Brackets); err != nil {
+		return err
+	}
+
+	if err := checkLevies(table.Levies); err != nil {
+		return err
+	}
+
+	if err := checkOffsets(table.Offsets); err != nil {
+		return err
+	}
+
+	if err := checkRepaymentBands(table.StudyLoan); err != nil {
+		return err
+	}
+
+	if err := table.Super.check(); err != nil {
+		return err
+	}
+
+	return table.checkScales()
+}
+
+// checkBrackets applies the same sanity checks to a set of brackets as ReadTaxBracketsConfig
+func checkBrackets(brackets []*IncomeTaxBracket) error {
+	if len(brackets) == 0 {
+		return fmt.Errorf("No valid tax brackets found")
+	}
+
+	prevUpper := Money.Amount(0)
+	for i, brac := range brackets {
+		if brac == nil {
+			return fmt.Errorf("Empty tax bracket %d", i+1)
+		}
+
+		top := brac.Upper == 0
+		if top && i != len(brackets)-1 {
+			return fmt.Errorf("Only the last tax bracket may have no upper limit (bracket %d)", i+1)
+		}
+
+		if err := checkBracket(brac, top, i, prevUpper); err != nil {
+			return fmt.Errorf("%v in tax bracket %d", err, i+1)
+		}
+
+		prevUpper = brac.Upper
+	}
+
+	return nil
+}
+
+// String describes the table by jurisdiction and financial year e.g. "Australia 2023-24"
+func (table *TaxTable) String() string {
+	return strings.TrimSpace(table.Jurisdiction + " " + table.FinancialYear)
+}
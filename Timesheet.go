@@ -0,0 +1,265 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PenaltyRates are the multipliers applied to the hourly rate for hours worked on weekends and public holidays.
+// A public holiday falling on a weekend is paid at the public holiday rate.
+type PenaltyRates struct {
+	Saturday      Money.Quantity
+	Sunday        Money.Quantity
+	PublicHoliday Money.Quantity
+}
+
+// Penalties are the penalty rates applied to timesheet hours. By default there are none: every hour is paid at the hourly rate.
+var Penalties = PenaltyRates{Saturday: Money.QuantityScale, Sunday: Money.QuantityScale, PublicHoliday: Money.QuantityScale}
+
+// names of the penalty rates as accepted by ParsePenaltyRates
+var penaltyFields = []string{"saturday", "sunday", "holiday"}
+
+// ParsePenaltyRates reads penalty rates of the form "saturday=1.25,sunday=1.5,holiday=2.5". Days not given are paid at the hourly rate.
+func ParsePenaltyRates(s string) (PenaltyRates, error) {
+	rates := PenaltyRates{Saturday: Money.QuantityScale, Sunday: Money.QuantityScale, PublicHoliday: Money.QuantityScale}
+
+	mapping, err := parseMapping(s, penaltyFields)
+	if err != nil {
+		return rates, err
+	}
+
+	for day, value := range mapping {
+		multiplier, err := Money.ParseQuantity(value)
+		if err != nil || multiplier < Money.QuantityScale {
+			return rates, fmt.Errorf("Invalid %s penalty rate <%s>: must be a multiplier of at least 1", day, value)
+		}
+
+		switch day {
+		case "saturday":
+			rates.Saturday = multiplier
+		case "sunday":
+			rates.Sunday = multiplier
+		case "holiday":
+			rates.PublicHoliday = multiplier
+		}
+	}
+
+	return rates, nil
+}
+
+// String formats penalty rates in the form accepted by ParsePenaltyRates
+func (p PenaltyRates) String() string {
+	return fmt.Sprintf("saturday=%s,sunday=%s,holiday=%s", p.Saturday, p.Sunday, p.PublicHoliday)
+}
+
+// Set parses penalty rates into p, allowing PenaltyRates to be used directly as a command line flag
+func (p *PenaltyRates) Set(s string) error {
+	parsed, err := ParsePenaltyRates(s)
+	if err != nil {
+		return err
+	}
+
+	*p = parsed
+	return nil
+}
+
+// Multiplier returns the penalty rate for hours worked on a date
+func (p PenaltyRates) Multiplier(date time.Time) Money.Quantity {
+	if _, ok := PublicHolidays[date]; ok {
+		return p.PublicHoliday
+	}
+
+	switch date.Weekday() {
+	case time.Saturday:
+		return p.Saturday
+	case time.Sunday:
+		return p.Sunday
+	}
+
+	return Money.QuantityScale
+}
+
+// PublicHolidays are the dates paid at the public holiday penalty rate, with the name of each holiday
+var PublicHolidays = map[time.Time]string{}
+
+// HolidayFields are the names of the columns of a public holidays file, which must have a header row naming them
+var HolidayFields = []string{"date", "name"}
+
+// ReadHolidays reads a public holidays file of dates (as YYYY-MM-DD) and optional holiday names
+func ReadHolidays(inputFile string) (map[time.Time]string, error) {
+	holidays := map[time.Time]string{}
+	var bad error
+
+	err := readLines(inputFile, HolidayFields, 1, func(row []string, index map[string]int, line int) {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(cell(row, index, "date")))
+		if err != nil && bad == nil {
+			bad = fmt.Errorf("Invalid public holiday date <%s> on line %d of <%s>", cell(row, index, "date"), line, inputFile)
+		}
+
+		holidays[date] = strings.TrimSpace(cell(row, index, "name"))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if bad != nil {
+		return nil, bad
+	}
+
+	return holidays, nil
+}
+
+// TimesheetLine is a day's hours worked by an hourly employee, read from a timesheet file
+type TimesheetLine struct {
+	Employee string    // employee ID, or full name, of the employee who worked the hours
+	Date     time.Time // day the hours were worked
+	Hours    Money.Quantity
+	Rate     Money.Amount // hourly rate for these hours, or zero for the employee's usual rate
+	Line     int          // line number in the timesheet file
+}
+
+// TimesheetAmount is the pay for one timesheet line
+type TimesheetAmount struct {
+	Date       string         `json:"date"` // as YYYY-MM-DD
+	Hours      Money.Quantity `json:"hours"`
+	Rate       Money.Amount   `json:"rate"`       // hourly rate before penalties
+	Multiplier Money.Quantity `json:"multiplier"` // penalty rate applied (1 for none)
+	Amount     Money.Amount   `json:"amount"`
+}
+
+// TimesheetFields are the names of the columns of a timesheet file, which must have a header row naming them (in any order).
+// The first three are required.
+var TimesheetFields = []string{"employee", "date", "hours", "rate"}
+
+// number of required timesheet file columns
+const requiredTimesheetFields = 3
+
+// ReadTimesheet reads the lines of a timesheet file in file order, along with a report of the problems found with invalid rows
+// (which are left out) and any error encountered reading the file
+func ReadTimesheet(inputFile string) ([]*TimesheetLine, ValidationReport, error) {
+	lines := []*TimesheetLine{}
+	report := ValidationReport{}
+
+	err := readLines(inputFile, TimesheetFields, requiredTimesheetFields, func(row []string, index map[string]int, line int) {
+		field := func(name string) string { return cell(row, index, name) }
+		rowReport := ValidationReport{}
+
+		ts := &TimesheetLine{Employee: strings.TrimSpace(field("employee")), Line: line}
+		if ts.Employee == "" {
+			rowReport.add("employee", field("employee"), "employee name is required")
+		}
+
+		var err error
+		if ts.Date, err = time.Parse("2006-01-02", strings.TrimSpace(field("date"))); err != nil {
+			rowReport.add("date", field("date"), "not a valid date (YYYY-MM-DD)")
+		}
+
+		if ts.Hours, err = Money.ParseQuantity(field("hours")); err != nil || ts.Hours <= 0 || ts.Hours > 24*Money.QuantityScale {
+			rowReport.add("hours", field("hours"), "hours must be a number greater than 0 and no more than 24")
+		}
+
+		if s := strings.TrimSpace(field("rate")); s != "" {
+			if ts.Rate, err = Money.Parse(s); err != nil || ts.Rate <= 0 {
+				rowReport.add("rate", s, "hourly rate must be a money value greater than zero")
+			}
+		}
+
+		if len(rowReport) > 0 {
+			rowReport.locate(inputFile, line)
+			rowReport.about(ts.Employee)
+			report = append(report, rowReport...)
+			return
+		}
+
+		lines = append(lines, ts)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lines, report, nil
+}
+
+// AttachTimesheet adds each timesheet line read from timesheetFile to the valid record of its employee (given by employee ID or by
+// name) whose pay period includes its date. Lines matching no record, or the record of an employee without an hourly rate, are reported.
+func AttachTimesheet(timesheetFile string, records []*PayrollRecord, lines []*TimesheetLine) ValidationReport {
+	report := ValidationReport{}
+	shared := sharedNames(records)
+
+	for _, ts := range lines {
+		if shared[normaliseKey(ts.Employee)] {
+			report = append(report, &ValidationError{File: timesheetFile, Line: ts.Line, Field: "employee", Value: ts.Employee,
+				Reason: "more than one employee has this name: give their employee ID"})
+			continue
+		}
+
+		var match *PayrollRecord
+		for _, rec := range records {
+			if rec.Valid && rec.isEmployee(ts.Employee) && !ts.Date.Before(rec.Period.Start) && !ts.Date.After(rec.Period.End) {
+				match = rec
+				break
+			}
+		}
+
+		switch {
+		case match == nil:
+			report = append(report, &ValidationError{File: timesheetFile, Line: ts.Line, Field: "employee", Value: ts.Employee,
+				Reason: fmt.Sprintf("no valid payroll record for this employee with a pay period including %s", ts.Date.Format("2006-01-02"))})
+		case match.HourlyRate == 0 && ts.Rate == 0:
+			report = append(report, &ValidationError{File: timesheetFile, Line: ts.Line, Field: "rate", Value: "",
+				Reason: fmt.Sprintf("%s has no hourly rate: give one in the input file or timesheet", ts.Employee)})
+		default:
+			match.Timesheet = append(match.Timesheet, ts)
+		}
+	}
+
+	return report
+}
+
+// calculate the pay for each timesheet line of this payroll record, in date order: hours at the hourly rate times the penalty rate
+// for the day, rounded to the cent
+func (rec *PayrollRecord) TimesheetLines() []TimesheetAmount {
+	lines := append([]*TimesheetLine{}, rec.Timesheet...)
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Date.Before(lines[j].Date) })
+
+	amounts := []TimesheetAmount{}
+	for _, ts := range lines {
+		rate := ts.Rate
+		if rate == 0 {
+			rate = rec.HourlyRate
+		}
+
+		multiplier := Penalties.Multiplier(ts.Date)
+		amount := rate.Exact().MulQuantity(ts.Hours).MulQuantity(multiplier).Round(Money.Rounding{Precision: Money.Cents})
+		amounts = append(amounts, TimesheetAmount{ts.Date.Format("2006-01-02"), ts.Hours, rate, multiplier, amount})
+	}
+
+	return amounts
+}
+
+// timesheetPay returns the total pay for the record's timesheet lines, and the total hours worked
+func (rec *PayrollRecord) timesheetPay() (Money.Amount, Money.Quantity) {
+	pay, hours := Money.Amount(0), Money.Quantity(0)
+	for _, line := range rec.TimesheetLines() {
+		pay += line.Amount
+		hours += line.Hours
+	}
+
+	return pay, hours
+}
+
+// hasTimesheets reports whether any valid record is paid from a timesheet
+func hasTimesheets(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if rec.Valid && (rec.HourlyRate != 0 || len(rec.Timesheet) > 0) {
+			return true
+		}
+	}
+
+	return false
+}
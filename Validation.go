@@ -0,0 +1,180 @@
+package PayrollRecord
+
+import (
+	"TaxBracket"
+	"fmt"
+	"strings"
+)
+
+// ValidationError describes a problem found with an input record, identifying the row and field at fault
+type ValidationError struct {
+	File   string `json:"file"`   // input file name
+	Line   int    `json:"line"`   // line number of the record in the input file (first line is 1)
+	Field  string `json:"field"`  // input field name e.g. "salary" (see InputFields), or empty if the problem is with the row as a whole
+	Value  string `json:"value"`  // offending value as given in the input file
+	Reason string `json:"reason"` // what is wrong with the value
+
+	Employee string `json:"employee,omitempty"` // employee an invalid timesheet, earnings or deductions row is for, if given (see RejectEmployees)
+}
+
+// Error formats a validation error e.g. "input.csv line 3: salary <abc>: not a valid money value"
+func (e *ValidationError) Error() string {
+	where := ""
+	if e.File != "" {
+		where = e.File + " "
+	}
+	if e.Line > 0 {
+		where += fmt.Sprintf("line %d", e.Line)
+	}
+	if where != "" {
+		where = strings.TrimSpace(where) + ": "
+	}
+
+	if e.Field == "" {
+		return fmt.Sprintf("%s%s <%s>", where, e.Reason, e.Value)
+	}
+
+	return fmt.Sprintf("%s%s <%s>: %s", where, e.Field, e.Value, e.Reason)
+}
+
+// ValidationReport is the set of validation errors found reading payroll input records. A non-empty report can be used as an error.
+type ValidationReport []*ValidationError
+
+// Error summarises a validation report, listing each validation error on its own line
+func (r ValidationReport) Error() string {
+	lines := []string{fmt.Sprintf("%d validation error(s) in payroll input", len(r))}
+	for _, e := range r {
+		lines = append(lines, e.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// add appends a validation error for a field of an input record
+func (r *ValidationReport) add(field, value, reason string) {
+	*r = append(*r, &ValidationError{Field: field, Value: strings.TrimSpace(value), Reason: reason})
+}
+
+// locate sets the input file and line number on each error in the report
+func (r ValidationReport) locate(file string, line int) {
+	for _, e := range r {
+		e.File = file
+		e.Line = line
+	}
+}
+
+// about sets the employee an invalid timesheet, earnings or deductions row is for on each error in the report
+func (r ValidationReport) about(employee string) {
+	for _, e := range r {
+		e.Employee = strings.TrimSpace(employee)
+	}
+}
+
+// RejectEmployees marks invalid each valid record read from inputFile for an employee (given by employee ID or by name) with an invalid
+// timesheet, earnings or deductions row in report: the row is left out, so paying them without it would pay the wrong amount.
+// The problems found with the records are returned.
+func RejectEmployees(inputFile string, records []*PayrollRecord, report ValidationReport) ValidationReport {
+	rejected := ValidationReport{}
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		recReport := ValidationReport{}
+		for _, verr := range report {
+			if verr.Employee != "" && rec.isEmployee(verr.Employee) {
+				recReport.add("", verr.Error(), "invalid row for this employee")
+			}
+		}
+
+		if len(recReport) > 0 {
+			recReport.locate(inputFile, rec.Line)
+			rec.Valid = false
+			rec.ErrorStr = fmt.Sprintf("Invalid input record: %v", rec.Row)
+			rec.Errors = recReport
+			rejected = append(rejected, recReport...)
+		}
+	}
+
+	return rejected
+}
+
+// checkEmployeeIDs checks each employee ID read from inputFile identifies just one employee: records sharing an ID must have the same
+// name, and pay periods that don't overlap. Records breaking the rule (all but the first of those sharing the ID) are marked invalid,
+// and the problems found returned.
+func checkEmployeeIDs(inputFile string, records []*PayrollRecord) ValidationReport {
+	report := ValidationReport{}
+	seen := map[string][]*PayrollRecord{} // valid records found so far for each employee ID
+
+	for _, rec := range records {
+		if !rec.Valid || rec.EmployeeID == "" {
+			continue
+		}
+
+		key := normaliseKey(rec.EmployeeID)
+		recReport := ValidationReport{}
+		for _, other := range seen[key] {
+			if normaliseKey(other.FullName()) != normaliseKey(rec.FullName()) {
+				recReport.add("id", rec.EmployeeID, fmt.Sprintf("employee ID is already used for %s on line %d", other.FullName(), other.Line))
+				break
+			}
+
+			if !rec.Period.Start.After(other.Period.End) && !other.Period.Start.After(rec.Period.End) {
+				recReport.add("id", rec.EmployeeID, fmt.Sprintf("pay period overlaps the pay period for this employee ID on line %d", other.Line))
+				break
+			}
+		}
+
+		if len(recReport) > 0 {
+			recReport.locate(inputFile, rec.Line)
+			rec.Valid = false
+			rec.ErrorStr = fmt.Sprintf("Invalid input record: %v", rec.Row)
+			rec.Errors = recReport
+			report = append(report, recReport...)
+			continue
+		}
+
+		seen[key] = append(seen[key], rec)
+	}
+
+	return report
+}
+
+// CheckTaxTables checks each valid record read from inputFile can be taxed: that a tax table is in force for its pay period and
+// that the table has the record's tax scale, and study loan repayment bands if the employee has a study loan. With a YTD store, the
+// record's pay period must not already have been posted to it, and the record's pay must work out. Records that can't be taxed are
+// marked invalid, so they are rejected rather than stopping the output, and the problems found returned.
+func CheckTaxTables(inputFile string, records []*PayrollRecord, registry *TaxBracket.Registry) ValidationReport {
+	report := ValidationReport{}
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		recReport := ValidationReport{}
+		table, err := rec.TaxTable(registry)
+		if err != nil {
+			recReport.add("period", rec.Period.Text, err.Error())
+		} else if _, err := table.Scale(rec.Scale); err != nil {
+			recReport.add("scale", rec.Scale, err.Error())
+		} else if rec.StudyLoan && len(table.StudyLoan) == 0 {
+			recReport.add("help", "yes", fmt.Sprintf("tax table <%s> has no study loan repayment bands", table.Describe()))
+		} else if YTD.posted(table, rec) {
+			recReport.add("period", rec.Period.Text, fmt.Sprintf("pay period already posted to YTD store <%s>", YTD.File))
+		} else if _, err := rec.Payslip(registry); err != nil {
+			recReport.add("", strings.Join(rec.Row, ","), err.Error())
+		}
+
+		if len(recReport) > 0 {
+			recReport.locate(inputFile, rec.Line)
+			rec.Valid = false
+			rec.ErrorStr = fmt.Sprintf("Invalid input record: %v", rec.Row)
+			rec.Errors = recReport
+			report = append(report, recReport...)
+		}
+	}
+
+	return report
+}
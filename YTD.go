@@ -0,0 +1,257 @@
+package PayrollRecord
+
+import (
+	"Money"
+	"TaxBracket"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// YTDTotals are an employee's year-to-date totals of the output figures for a financial year. Gross less pre-tax deductions, tax,
+// levies, study loan repayments and post-tax deductions comes to Net, give or take the rounding of each payslip's net pay.
+type YTDTotals struct {
+	Gross      Money.Amount `json:"gross"`
+	Deductions Money.Amount `json:"pre_tax_deductions"`
+	Tax        Money.Amount `json:"tax"`
+	Levies     Money.Amount `json:"levies"`
+	StudyLoan  Money.Amount `json:"study_loan"`
+	PostTax    Money.Amount `json:"post_tax_deductions"`
+	Net        Money.Amount `json:"net"`
+	Super      Money.Amount `json:"super"`
+}
+
+// add adds a payslip's figures to the totals
+func (t *YTDTotals) add(p *Payslip) {
+	t.Gross += p.Gross
+	t.Deductions += totalOf(p.Deductions)
+	t.Tax += p.Tax
+	for _, levy := range p.Levies {
+		t.Levies += levy.Amount
+	}
+	t.StudyLoan += p.StudyLoan
+	t.PostTax += totalOf(p.PostTax)
+	t.Net += p.Net
+	t.Super += p.Super
+}
+
+// YTDAccount is an employee's entry in a YTD store: their totals for a financial year and the pay periods posted to them
+type YTDAccount struct {
+	YTDTotals
+	Periods []string `json:"periods"` // pay periods posted, as YYYY-MM-DD/YYYY-MM-DD
+}
+
+// YTDStore holds year-to-date totals carried between runs, kept in a JSON file. Accounts are keyed by financial year (see ytdYear)
+// and then employee (see employeeKey).
+type YTDStore struct {
+	File  string                            `json:"-"` // file the store was read from, and is written back to by Save
+	Years map[string]map[string]*YTDAccount `json:"years"`
+}
+
+// YTD is the year-to-date store in use, or nil if there is none: with a store, records already posted to it are rejected
+// (see CheckTaxTables), concessional contributions carry over from it (see TrackConcessionalCap) and YTD totals are output
+var YTD *YTDStore
+
+// ReadYTDStore reads a YTD store from a JSON file. A file that doesn't exist yet gives an empty store, created by Save.
+func ReadYTDStore(inputFile string) (*YTDStore, error) {
+	store := &YTDStore{File: inputFile, Years: map[string]map[string]*YTDAccount{}}
+
+	data, err := os.ReadFile(inputFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading YTD store <%s>: %v", inputFile, err)
+	}
+
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("Error reading YTD store <%s>: %v", inputFile, err)
+	}
+
+	if store.Years == nil {
+		store.Years = map[string]map[string]*YTDAccount{}
+	}
+
+	return store, nil
+}
+
+// ytdYear returns the key of the financial year taxed by a table in a YTD store: its jurisdiction and financial year e.g.
+// "Australia 2023-24", or failing that its effective dates e.g. "2023-07-01/2024-06-30". A table with neither (such as a CSV
+// tax table) can't be used with a YTD store, as nothing says which year its totals belong to.
+func ytdYear(table *TaxBracket.TaxTable) (string, error) {
+	if table.FinancialYear != "" {
+		return table.String(), nil
+	}
+
+	if !table.From.IsZero() && !table.To.IsZero() {
+		return table.EffectiveFrom + "/" + table.EffectiveTo, nil
+	}
+
+	return "", fmt.Errorf("Tax table <%s> has no financial year or effective dates to keep year-to-date totals by", table.Describe())
+}
+
+// yearKey returns the key of the financial year taxed by a table, as in a YTD store (see ytdYear), so totals run on across a
+// change of table part way through a year. A table with neither a financial year nor effective dates, only usable without a
+// store, is a year of its own.
+func yearKey(table *TaxBracket.TaxTable) string {
+	if year, err := ytdYear(table); err == nil {
+		return year
+	}
+
+	return table.Describe()
+}
+
+// CheckTables checks each table in a registry has a financial year or effective dates to key year-to-date totals by (see ytdYear).
+// A nil store needs neither.
+func (store *YTDStore) CheckTables(registry *TaxBracket.Registry) error {
+	if store == nil {
+		return nil
+	}
+
+	for _, table := range registry.Tables {
+		if _, err := ytdYear(table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// account returns the employee's account for the financial year taxed by a table, or nil if nothing has been posted to it.
+// A nil store has no accounts.
+func (store *YTDStore) account(table *TaxBracket.TaxTable, key string) *YTDAccount {
+	if store == nil {
+		return nil
+	}
+
+	year, err := ytdYear(table)
+	if err != nil {
+		return nil // refused by CheckTables
+	}
+
+	return store.Years[year][key]
+}
+
+// periodKey identifies a record's pay period in a YTD account
+func (rec *PayrollRecord) periodKey() string {
+	return rec.Period.Start.Format("2006-01-02") + "/" + rec.Period.End.Format("2006-01-02")
+}
+
+// posted reports whether the record's pay period has already been posted to its employee's account
+func (store *YTDStore) posted(table *TaxBracket.TaxTable, rec *PayrollRecord) bool {
+	if account := store.account(table, rec.employeeKey()); account != nil {
+		for _, period := range account.Periods {
+			if period == rec.periodKey() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// AccumulateYTD works through the valid records in order, setting each one's YTD to its employee's totals for the financial
+// year including it: the totals in the YTD store, plus the records before it in the run in the same financial year (see yearKey). It does nothing
+// without a YTD store.
+func AccumulateYTD(records []*PayrollRecord, registry *TaxBracket.Registry) error {
+	if YTD == nil {
+		return nil
+	}
+
+	ytd := map[string]map[string]YTDTotals{}
+
+	for _, rec := range records {
+		if !rec.Valid {
+			continue
+		}
+
+		table, err := rec.TaxTable(registry)
+		if err != nil {
+			return fmt.Errorf("Error getting year-to-date totals: %v", err)
+		}
+
+		year := yearKey(table)
+		if ytd[year] == nil {
+			ytd[year] = map[string]YTDTotals{}
+		}
+
+		key := rec.employeeKey()
+		totals, ok := ytd[year][key]
+		if account := YTD.account(table, key); !ok && account != nil {
+			totals = account.YTDTotals
+		}
+
+		payslip, err := rec.Payslip(registry)
+		if err != nil {
+			return err
+		}
+
+		totals.add(payslip)
+		ytd[year][key] = totals
+		rec.YTD = &totals
+	}
+
+	return nil
+}
+
+// Post updates the store with the year-to-date totals of the valid records (see AccumulateYTD), recording their pay periods as posted
+func (store *YTDStore) Post(records []*PayrollRecord, registry *TaxBracket.Registry) error {
+	for _, rec := range records {
+		if !rec.Valid || rec.YTD == nil {
+			continue
+		}
+
+		table, err := rec.TaxTable(registry)
+		if err != nil {
+			return fmt.Errorf("Error posting year-to-date totals: %v", err)
+		}
+
+		year, err := ytdYear(table)
+		if err != nil {
+			return fmt.Errorf("Error posting year-to-date totals: %v", err)
+		}
+
+		if store.Years[year] == nil {
+			store.Years[year] = map[string]*YTDAccount{}
+		}
+
+		key := rec.employeeKey()
+		account := store.Years[year][key]
+		if account == nil {
+			account = &YTDAccount{Periods: []string{}}
+			store.Years[year][key] = account
+		}
+
+		account.YTDTotals = *rec.YTD
+		account.Periods = append(account.Periods, rec.periodKey())
+	}
+
+	return nil
+}
+
+// Save writes the store back to its file. The file is replaced in one step (see writeFile), so a failed run never
+// leaves it partly written.
+func (store *YTDStore) Save() error {
+	return replaceFile(store.File, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(store); err != nil {
+			return fmt.Errorf("Error writing YTD store <%s>: %v", store.File, err)
+		}
+
+		return nil
+	})
+}
+
+// hasYTD reports whether any valid record has year-to-date totals
+func hasYTD(records []*PayrollRecord) bool {
+	for _, rec := range records {
+		if rec.Valid && rec.YTD != nil {
+			return true
+		}
+	}
+
+	return false
+}